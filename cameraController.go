@@ -0,0 +1,181 @@
+package main
+
+import (
+	"math"
+
+	"github.com/Trip1eLift/3d-engine-go/graphicsMath"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// Both controllers drive the engine's camera. Give them a lower priority
+// than, or add them before, the elements that draw so those see this
+// frame's camera. They move in fixed updates and place the camera between
+// the last two at render time, with the alpha the other elements
+// interpolate with.
+
+const maxPitch = float32(89.0 / 180.0 * math.Pi)
+
+func clampPitch(fPitch float32) float32 {
+	if fPitch > maxPitch {
+		return maxPitch
+	}
+	if fPitch < -maxPitch {
+		return -maxPitch
+	}
+	return fPitch
+}
+
+func lerp(a float32, b float32, t float32) float32 {
+	return a + (b-a)*t
+}
+
+// ---------------------------- fly camera --------------------------
+
+// flyCamera is a first-person controller: WASD to move, Space/Left Shift
+// to rise and sink, mouse to look around.
+type flyCamera struct {
+	graphics    *openglGraphicsEngine
+	speed       float32           // units per second
+	sensitivity float32           // radians per pixel
+	position    graphicsMath.Vec3 // after the last update, rendered interpolated
+	fYaw        float32
+	fPitch      float32
+	// At the previous update, for onRender
+	previousPosition graphicsMath.Vec3
+	previousYaw      float32
+	previousPitch    float32
+}
+
+func newFlyCamera(container *openglGraphicsEngine) *flyCamera {
+	var c flyCamera
+	c.graphics = container
+	c.speed = 3.0
+	c.sensitivity = 0.003
+	return &c
+}
+
+func (c *flyCamera) onCreate() bool {
+	c.position = c.graphics.camera.Position
+	c.previousPosition = c.position
+	c.graphics.SetCursorCaptured(true)
+	return true
+}
+
+func (c *flyCamera) onDestroy() {
+	c.graphics.SetCursorCaptured(false)
+}
+
+func (c *flyCamera) onUpdate() bool {
+	camera := c.graphics.camera
+	c.previousPosition, c.previousYaw, c.previousPitch = c.position, c.fYaw, c.fPitch
+	dx, dy := c.graphics.MouseDelta()
+	c.fYaw += float32(dx) * c.sensitivity
+	c.fPitch = clampPitch(c.fPitch + float32(dy)*c.sensitivity)
+	camera.Orientation = graphicsMath.QuatFromEuler(c.fPitch, c.fYaw, 0)
+
+	var move graphicsMath.Vec3
+	if c.graphics.IsKeyDown(glfw.KeyW) {
+		move = move.Add(camera.Forward())
+	}
+	if c.graphics.IsKeyDown(glfw.KeyS) {
+		move = move.Sub(camera.Forward())
+	}
+	if c.graphics.IsKeyDown(glfw.KeyD) {
+		move = move.Add(camera.Right())
+	}
+	if c.graphics.IsKeyDown(glfw.KeyA) {
+		move = move.Sub(camera.Right())
+	}
+	if c.graphics.IsKeyDown(glfw.KeySpace) {
+		move.Y += 1
+	}
+	if c.graphics.IsKeyDown(glfw.KeyLeftShift) {
+		move.Y -= 1
+	}
+	step := c.speed * float32(c.graphics.delta)
+	c.position = c.position.Add(move.Normalize().Scale(step))
+	camera.Position = c.position
+	return true
+}
+
+func (c *flyCamera) onRender(alpha float64) bool {
+	camera := c.graphics.camera
+	t := float32(alpha)
+	camera.Orientation = graphicsMath.QuatFromEuler(lerp(c.previousPitch, c.fPitch, t), lerp(c.previousYaw, c.fYaw, t), 0)
+	camera.Position = c.previousPosition.Lerp(c.position, t)
+	return true
+}
+
+// ---------------------------- orbit camera --------------------------
+
+// orbitCamera circles a target point: drag with the left mouse button to
+// rotate, scroll to zoom.
+type orbitCamera struct {
+	graphics    *openglGraphicsEngine
+	target      graphicsMath.Vec3
+	distance    float32
+	minDistance float32
+	maxDistance float32
+	sensitivity float32 // radians per pixel
+	zoomSpeed   float32 // fraction of the distance per scroll step
+	fYaw        float32
+	fPitch      float32
+	// At the previous update, for onRender
+	previousYaw      float32
+	previousPitch    float32
+	previousDistance float32
+}
+
+func newOrbitCamera(container *openglGraphicsEngine, target graphicsMath.Vec3, distance float32) *orbitCamera {
+	var c orbitCamera
+	c.graphics = container
+	c.target = target
+	c.distance = distance
+	c.minDistance = 0.5
+	c.maxDistance = 100
+	c.sensitivity = 0.01
+	c.zoomSpeed = 0.1
+	return &c
+}
+
+func (c *orbitCamera) onCreate() bool {
+	c.previousYaw, c.previousPitch, c.previousDistance = c.fYaw, c.fPitch, c.distance
+	c.place(c.fYaw, c.fPitch, c.distance)
+	return true
+}
+
+func (c *orbitCamera) onDestroy() {}
+
+func (c *orbitCamera) onUpdate() bool {
+	c.previousYaw, c.previousPitch, c.previousDistance = c.fYaw, c.fPitch, c.distance
+	if c.graphics.IsMouseButtonDown(glfw.MouseButtonLeft) {
+		dx, dy := c.graphics.MouseDelta()
+		c.fYaw += float32(dx) * c.sensitivity
+		c.fPitch = clampPitch(c.fPitch + float32(dy)*c.sensitivity)
+	}
+
+	_, scrollY := c.graphics.ScrollDelta()
+	c.distance *= 1 - float32(scrollY)*c.zoomSpeed
+	if c.distance < c.minDistance {
+		c.distance = c.minDistance
+	}
+	if c.distance > c.maxDistance {
+		c.distance = c.maxDistance
+	}
+
+	c.place(c.fYaw, c.fPitch, c.distance)
+	return true
+}
+
+func (c *orbitCamera) onRender(alpha float64) bool {
+	t := float32(alpha)
+	c.place(lerp(c.previousYaw, c.fYaw, t), lerp(c.previousPitch, c.fPitch, t), lerp(c.previousDistance, c.distance, t))
+	return true
+}
+
+// place puts the camera on its sphere around target, facing it.
+func (c *orbitCamera) place(fYaw float32, fPitch float32, distance float32) {
+	camera := c.graphics.camera
+	camera.Orientation = graphicsMath.QuatFromEuler(fPitch, fYaw, 0)
+	camera.Position = c.target.Sub(camera.Forward().Scale(distance))
+}
@@ -0,0 +1,166 @@
+package consoleGraphics
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Pixels store real colors. They are only reduced to what the terminal can
+// show when written out, by the engine's colorMode.
+
+type RGB struct {
+	red, green, blue uint8
+}
+
+// Scale darkens, or brightens and saturates, the color by factor.
+func (color RGB) Scale(factor float32) RGB {
+	scale := func(channel uint8) uint8 {
+		value := float32(channel) * factor
+		if value > 255 {
+			return 255
+		}
+		if value < 0 {
+			return 0
+		}
+		return uint8(value)
+	}
+	return RGB{scale(color.red), scale(color.green), scale(color.blue)}
+}
+
+type colorMode int
+
+const (
+	COLOR_AUTO      colorMode = iota // detect from COLORTERM and TERM
+	COLOR_TRUECOLOR                  // 24 bit, 38;2;r;g;b
+	COLOR_256                        // xterm 256 color palette
+	COLOR_16                         // the basic ANSI colors and their bright versions
+)
+
+// detectColorMode follows the common conventions: COLORTERM advertises
+// truecolor, TERM names like xterm-256color advertise the 256 palette.
+func detectColorMode() colorMode {
+	colorterm := os.Getenv("COLORTERM")
+	if colorterm == "truecolor" || colorterm == "24bit" {
+		return COLOR_TRUECOLOR
+	}
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return COLOR_256
+	}
+	return COLOR_16
+}
+
+// colorEscape is the escape setting the foreground, or the background, to
+// the terminal's closest match of color.
+func (mode colorMode) colorEscape(color RGB, background bool) string {
+	switch mode {
+	case COLOR_TRUECOLOR:
+		layer := 38
+		if background {
+			layer = 48
+		}
+		return fmt.Sprintf("\033[%d;2;%d;%d;%dm", layer, color.red, color.green, color.blue)
+	case COLOR_256:
+		layer := 38
+		if background {
+			layer = 48
+		}
+		return fmt.Sprintf("\033[%d;5;%dm", layer, nearest256(color))
+	default:
+		// 30-37 and 90-97 for foregrounds, 10 more for backgrounds
+		index := nearest16(color)
+		code := 30 + index
+		if index >= 8 {
+			code = 90 + index - 8
+		}
+		if background {
+			code += 10
+		}
+		return fmt.Sprintf("\033[%dm", code)
+	}
+}
+
+// escapeKey is a color on one layer, foreground or background.
+type escapeKey struct {
+	color      RGB
+	background bool
+}
+
+// Shading can make many colors; the cache starts over past this many.
+const MAX_CACHED_ESCAPES = 4096
+
+// cachedEscape is colorEscape, computed once per color.
+func (CGE *consoleGraphicEngine) cachedEscape(color RGB, background bool) string {
+	key := escapeKey{color, background}
+	if escape, ok := CGE.escapes[key]; ok {
+		return escape
+	}
+	if len(CGE.escapes) >= MAX_CACHED_ESCAPES {
+		CGE.escapes = make(map[escapeKey]string)
+	}
+	escape := CGE.colorMode.colorEscape(color, background)
+	CGE.escapes[key] = escape
+	return escape
+}
+
+// ---------------------------- palettes --------------------------
+
+// xterm's default 16 colors
+var palette16 = [16]RGB{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// Channel levels of the 6x6x6 color cube, indices 16 to 231
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+func distanceSquared(a RGB, r int, g int, b int) int {
+	dr, dg, db := int(a.red)-r, int(a.green)-g, int(a.blue)-b
+	return dr*dr + dg*dg + db*db
+}
+
+func nearest16(color RGB) int {
+	best, bestDistance := 0, -1
+	for index, entry := range palette16 {
+		distance := distanceSquared(color, int(entry.red), int(entry.green), int(entry.blue))
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = index, distance
+		}
+	}
+	return best
+}
+
+// nearest256 picks the closer of the nearest color cube entry and the nearest
+// step of the grayscale ramp, indices 232 to 255. The first 16 entries vary
+// between terminals and are not used.
+func nearest256(color RGB) int {
+	nearestLevel := func(channel uint8) int {
+		best := 0
+		for index, level := range cubeLevels {
+			if abs(int(channel)-level) < abs(int(channel)-cubeLevels[best]) {
+				best = index
+			}
+		}
+		return best
+	}
+	r, g, b := nearestLevel(color.red), nearestLevel(color.green), nearestLevel(color.blue)
+	cubeIndex := 16 + 36*r + 6*g + b
+	cubeDistance := distanceSquared(color, cubeLevels[r], cubeLevels[g], cubeLevels[b])
+
+	// Gray steps are 8, 18, ..., 238
+	average := (int(color.red) + int(color.green) + int(color.blue)) / 3
+	step := (average - 8 + 5) / 10
+	if step < 0 {
+		step = 0
+	}
+	if step > 23 {
+		step = 23
+	}
+	gray := 8 + 10*step
+	if distanceSquared(color, gray, gray, gray) < cubeDistance {
+		return 232 + step
+	}
+	return cubeIndex
+}
@@ -0,0 +1,90 @@
+package consoleGraphics
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNearestPalette(t *testing.T) {
+	cases := []struct {
+		name    string
+		color   RGB
+		want256 int
+		want16  int
+	}{
+		{"black", BLACK, 16, 0},
+		{"red", RED, 196, 9},
+		{"green", GREEN, 46, 10},
+		{"yellow", YELLOW, 226, 11},
+		{"blue", BLUE, 21, 4},
+		{"purple", PURPLE, 201, 13},
+		{"cyan", CYAN, 51, 14},
+		{"white", WHITE, 231, 15},
+		{"darkest gray", RGB{8, 8, 8}, 232, 0},
+		{"dark gray", RGB{50, 50, 50}, 236, 0},
+		{"middle gray", RGB{128, 128, 128}, 244, 8},
+		{"lightest gray", RGB{238, 238, 238}, 255, 7},
+		{"dark red", RGB{100, 0, 0}, 52, 0},
+		{"xterm red", RGB{205, 0, 0}, 160, 1},
+	}
+	for _, c := range cases {
+		if got := nearest256(c.color); got != c.want256 {
+			t.Errorf("nearest256(%s) = %d, want %d", c.name, got, c.want256)
+		}
+		if got := nearest16(c.color); got != c.want16 {
+			t.Errorf("nearest16(%s) = %d, want %d", c.name, got, c.want16)
+		}
+	}
+}
+
+func TestDetectColorMode(t *testing.T) {
+	cases := []struct {
+		colorterm string
+		term      string
+		want      colorMode
+	}{
+		{"truecolor", "xterm-256color", COLOR_TRUECOLOR},
+		{"24bit", "xterm", COLOR_TRUECOLOR},
+		{"", "xterm-256color", COLOR_256},
+		{"yes", "screen-256color", COLOR_256},
+		{"", "xterm", COLOR_16},
+		{"", "linux", COLOR_16},
+		{"", "", COLOR_16},
+	}
+	for _, c := range cases {
+		t.Setenv("COLORTERM", c.colorterm)
+		t.Setenv("TERM", c.term)
+		if got := detectColorMode(); got != c.want {
+			t.Errorf("COLORTERM=%q TERM=%q: %d, want %d", c.colorterm, c.term, got, c.want)
+		}
+	}
+}
+
+func TestComputeGraphicsWritesColorsOnce(t *testing.T) {
+	CGE := constructConsoleGraphicEngine(8, 2, WHITE, BLACK, COLOR_256)
+	var output bytes.Buffer
+	CGE.output = bufio.NewWriter(&output)
+	// Two reds the 256 palette cannot tell apart
+	CGE.fillRect(0, 0, 3, 0, FULL_BLOCK, RED, BLACK)
+	CGE.fillRect(4, 0, 7, 0, FULL_BLOCK, RGB{250, 0, 0}, BLACK)
+	CGE.rasterize()
+	CGE.computeGraphics()
+	CGE.render()
+
+	written := output.String()
+	for escape, want := range map[string]int{"\033[38;5;196m": 1, "\033[48;5;16m": 1, "\033[38;5;231m": 1} {
+		if got := strings.Count(written, escape); got != want {
+			t.Errorf("%q written %d times, want %d", escape, got, want)
+		}
+	}
+
+	// Nothing changed, nothing to write
+	output.Reset()
+	CGE.computeGraphics()
+	CGE.render()
+	if output.Len() != 0 {
+		t.Errorf("unchanged frame wrote %q", output.String())
+	}
+}
@@ -1,18 +1,26 @@
 package consoleGraphics
 
 import (
+	"bufio"
 	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"sort"
+
+	"github.com/Trip1eLift/3d-engine-go/graphicsMath"
 )
 
-const (
+var (
 	// COLOUR
-	RED    = "\033[31m"
-	GREEN  = "\033[32m"
-	YELLOW = "\033[33m"
-	BLUE   = "\033[34m"
-	PURPLE = "\033[35m"
-	CYAN   = "\033[36m"
-	WHITE  = "\033[37m"
+	BLACK  = RGB{0, 0, 0}
+	RED    = RGB{255, 0, 0}
+	GREEN  = RGB{0, 255, 0}
+	YELLOW = RGB{255, 255, 0}
+	BLUE   = RGB{0, 0, 255}
+	PURPLE = RGB{255, 0, 255}
+	CYAN   = RGB{0, 255, 255}
+	WHITE  = RGB{255, 255, 255}
 )
 
 // Unicode table:
@@ -27,9 +35,24 @@ const (
 	SPACE_BLOCK  = " "
 )
 
+// shadeBlock picks a denser block for brighter light, lum in [0, 1].
+func shadeBlock(lum float32) string {
+	switch {
+	case lum >= 0.75:
+		return FULL_BLOCK
+	case lum >= 0.5:
+		return DARK_BLOCK
+	case lum >= 0.25:
+		return MEDIUM_BLOCK
+	default:
+		return LIGHT_BLOCK
+	}
+}
+
 type pixel struct {
 	pixel_type string
-	color      string
+	color      RGB
+	background RGB
 }
 
 type consoleComponent interface {
@@ -41,97 +64,159 @@ type consoleGraphicEngine struct {
 	screenWidth  int
 	screenHeight int
 	pixels       []pixel
-	output       string
-	component    consoleComponent
+	depth        []float32     // per cell, cleared at the start of every rasterize
+	background   pixel         // every frame starts cleared to it
+	previous     []pixel       // what the terminal shows, compared against pixels
+	output       *bufio.Writer // one write per frame
+	colorMode    colorMode
+	escapes      map[escapeKey]string // colorMode's escapes of the colors seen so far
+	outputColor  RGB                  // last foreground written
+	outputBack   RGB                  // last background written
+	colorEscape  string               // escape of outputColor, empty before the first
+	backEscape   string               // escape of outputBack, empty before the first
+	components   []consoleComponent
+	camera       *graphicsMath.Camera
+	commands     []drawCommand // queued by the components this frame
+	tiles        []tile
+	workers      int
 }
 
-func constructConsoleGraphicEngine(width int, height int, color string) *consoleGraphicEngine {
+// constructConsoleGraphicEngine clears the screen to background. Pass
+// COLOR_AUTO to use the best mode the terminal advertises.
+func constructConsoleGraphicEngine(width int, height int, color RGB, background RGB, mode colorMode) *consoleGraphicEngine {
 	CGE := &consoleGraphicEngine{}
 	CGE.screenWidth = width
 	CGE.screenHeight = height
+	CGE.background = pixel{pixel_type: SPACE_BLOCK, color: color, background: background}
+	if mode == COLOR_AUTO {
+		mode = detectColorMode()
+	}
+	CGE.colorMode = mode
+	CGE.escapes = make(map[escapeKey]string)
 	pix_len := width * height
 	pixels := make([]pixel, pix_len)
 	for index := 0; index < pix_len; index++ {
-		pixels[index].pixel_type = SPACE_BLOCK
-		pixels[index].color = color
+		pixels[index] = CGE.background
 	}
 	CGE.pixels = pixels
+	CGE.depth = make([]float32, pix_len)
+	// Nothing matches the zero pixel, so the first frame is drawn in full
+	CGE.previous = make([]pixel, pix_len)
+	// Room for a whole frame of moves, colors and glyphs without flushing early
+	CGE.output = bufio.NewWriterSize(os.Stdout, pix_len*24)
+	CGE.output.WriteString("\033[2J")
+	CGE.camera = graphicsMath.NewCamera(float32(height) / float32(width))
+	CGE.tiles = splitTiles(width, height)
+	CGE.workers = runtime.GOMAXPROCS(0)
 	return CGE
 }
 
-func (CGE *consoleGraphicEngine) fillALL(pixel_type string, color string) {
-	for index := 0; index < len(CGE.pixels); index++ {
-		CGE.pixels[index].pixel_type = pixel_type
-		CGE.pixels[index].color = color
-	}
+// The draw functions only queue commands. They are rasterized together,
+// in the order they were queued, once every component has updated.
+//
+// Only the fills set backgrounds; everything else draws its glyph and color
+// over the background already in the cell.
+
+func (CGE *consoleGraphicEngine) fillALL(pixel_type string, color RGB, background RGB) {
+	CGE.fillRect(0, 0, CGE.screenWidth-1, CGE.screenHeight-1, pixel_type, color, background)
 }
 
-func (CGE *consoleGraphicEngine) drawPixel(x int, y int, pix_type string, pix_color string) {
-	if x >= 0 && x < CGE.screenWidth && y >= 0 && y < CGE.screenHeight {
-		target := y*CGE.screenWidth + x
-		CGE.pixels[target].color = pix_color
-		CGE.pixels[target].pixel_type = pix_type
-	}
+// fillRect covers the cells from (x1, y1) to (x2, y2) inclusive.
+func (CGE *consoleGraphicEngine) fillRect(x1 int, y1 int, x2 int, y2 int, pixel_type string, color RGB, background RGB) {
+	CGE.queue(drawCommand{kind: FILL_COMMAND, pix: pixel{pixel_type, color, background},
+		minX: minInt(x1, x2), minY: minInt(y1, y2), maxX: maxInt(x1, x2), maxY: maxInt(y1, y2)})
 }
 
-func (CGE *consoleGraphicEngine) drawLine(x1 int, y1 int, x2 int, y2 int, pix_type string, pix_color string) {
-	var x, y, xe, ye int
-	dx := x2 - x1
-	dy := y2 - y1
-	dx1 := abs(dx)
-	dy1 := abs(dy)
-	px := 2*dy1 - dx1
-	py := 2*dx1 - dy1
-	if dy1 <= dx1 {
-		if dx >= 0 {
-			x = x1
-			y = y1
-			xe = x2
-		} else {
-			x = x2
-			y = y2
-			xe = x1
-		}
-		go CGE.drawPixel(x, y, pix_type, pix_color)
-
-		for i := 0; x < xe; i++ {
-			x++
-			if px < 0 {
-				px += 2 * dy1
-			} else {
-				if (dx < 0 && dy < 0) || (dx > 0 && dy > 0) {
-					y++
-				} else {
-					y--
-				}
-				px += 2 * (dy1 - dx1)
-			}
-			go CGE.drawPixel(x, y, pix_type, pix_color)
+func (CGE *consoleGraphicEngine) drawPixel(x int, y int, pix_type string, pix_color RGB) {
+	CGE.queue(drawCommand{kind: PIXEL_COMMAND, pix: pixel{pixel_type: pix_type, color: pix_color},
+		x: [3]int{x}, y: [3]int{y}, minX: x, minY: y, maxX: x, maxY: y})
+}
+
+func (CGE *consoleGraphicEngine) drawLine(x1 int, y1 int, x2 int, y2 int, pix_type string, pix_color RGB) {
+	CGE.queue(drawCommand{kind: LINE_COMMAND, pix: pixel{pixel_type: pix_type, color: pix_color},
+		x: [3]int{x1, x2}, y: [3]int{y1, y2},
+		minX: minInt(x1, x2), minY: minInt(y1, y2), maxX: maxInt(x1, x2), maxY: maxInt(y1, y2)})
+}
+
+func (CGE *consoleGraphicEngine) drawTriangle(x1 int, y1 int, x2 int, y2 int, x3 int, y3 int, pix_type string, pix_color RGB) {
+	CGE.queue(drawCommand{kind: TRIANGLE_COMMAND, pix: pixel{pixel_type: pix_type, color: pix_color},
+		x: [3]int{x1, x2, x3}, y: [3]int{y1, y2, y3},
+		minX: minInt(x1, x2, x3), minY: minInt(y1, y2, y3), maxX: maxInt(x1, x2, x3), maxY: maxInt(y1, y2, y3)})
+}
+
+// fillTriangle draws a solid triangle at cell coordinates x, y. Cells keep
+// the nearest triangle, smallest z, of every fillTriangle this frame; lines
+// and pixels ignore depth and draw over whatever is queued before them.
+func (CGE *consoleGraphicEngine) fillTriangle(x1, y1, z1, x2, y2, z2, x3, y3, z3 float32, pix_type string, pix_color RGB) {
+	CGE.queue(drawCommand{kind: FILL_TRIANGLE_COMMAND, pix: pixel{pixel_type: pix_type, color: pix_color},
+		vx: [3]float32{x1, x2, x3}, vy: [3]float32{y1, y2, y3}, vz: [3]float32{z1, z2, z3},
+		minX: int(math.Floor(float64(min3(x1, x2, x3)))), minY: int(math.Floor(float64(min3(y1, y2, y3)))),
+		maxX: int(math.Ceil(float64(max3(x1, x2, x3)))), maxY: int(math.Ceil(float64(max3(y1, y2, y3))))})
+}
+
+func min3(a, b, c float32) float32 {
+	return float32(math.Min(float64(a), math.Min(float64(b), float64(c))))
+}
+
+func max3(a, b, c float32) float32 {
+	return float32(math.Max(float64(a), math.Max(float64(b), float64(c))))
+}
+
+// rasterLine calls plot for the cells of the Bresenham line from (x1, y1) to
+// (x2, y2) that lie inside clip. Each step's cell has a closed form, so the
+// walk starts and stops at clip's edges instead of visiting the whole line:
+// a tile pays for its own cells only, however long the line is.
+func rasterLine(x1 int, y1 int, x2 int, y2 int, clip tile, plot func(x int, y int)) {
+	xMajor := abs(y2-y1) <= abs(x2-x1)
+	// Walk from the end with the smaller major coordinate
+	if (xMajor && x2 < x1) || (!xMajor && y2 < y1) {
+		x1, y1, x2, y2 = x2, y2, x1, y1
+	}
+
+	// major is the axis stepped every cell, minor the one stepped sometimes
+	major, minor, length, rise := x1, y1, x2-x1, y2-y1
+	majorMin, majorMax, minorMin, minorMax := clip.minX, clip.maxX, clip.minY, clip.maxY
+	// Ties step the minor axis on x-major lines only
+	bias := length
+	if !xMajor {
+		major, minor, length, rise = y1, x1, y2-y1, x2-x1
+		majorMin, majorMax, minorMin, minorMax = clip.minY, clip.maxY, clip.minX, clip.maxX
+		bias = length - 1
+	}
+	minorDir := 1
+	if rise < 0 {
+		minorDir, rise = -1, -rise
+	}
+
+	// Minor steps taken after step i, never decreasing in i
+	offset := func(i int) int {
+		if length == 0 {
+			return 0
 		}
-	} else {
-		if dy >= 0 {
-			x = x1
-			y = y1
-			ye = y2
+		return (2*i*rise + bias) / (2 * length)
+	}
+
+	// Steps inside clip on the major axis
+	first := maxInt(0, majorMin-major)
+	last := minInt(length, majorMax-major)
+	if first > last {
+		return
+	}
+
+	// Offsets inside clip on the minor axis
+	offsetMin, offsetMax := minorMin-minor, minorMax-minor
+	if minorDir < 0 {
+		offsetMin, offsetMax = minor-minorMax, minor-minorMin
+	}
+	steps := last - first + 1
+	from := first + sort.Search(steps, func(n int) bool { return offset(first+n) >= offsetMin })
+	to := first + sort.Search(steps, func(n int) bool { return offset(first+n) > offsetMax }) - 1
+
+	for i := from; i <= to; i++ {
+		if xMajor {
+			plot(major+i, minor+minorDir*offset(i))
 		} else {
-			x = x2
-			y = y2
-			ye = y1
-		}
-		go CGE.drawPixel(x, y, pix_type, pix_color)
-		for i := 0; y < ye; i++ {
-			y++
-			if py <= 0 {
-				py += 2 * dx1
-			} else {
-				if (dx < 0 && dy < 0) || (dx > 0 && dy > 0) {
-					x++
-				} else {
-					x--
-				}
-				py += 2 * (dx1 - dy1)
-			}
-			go CGE.drawPixel(x, y, pix_type, pix_color)
+			plot(minor+minorDir*offset(i), major+i)
 		}
 	}
 }
@@ -143,50 +228,113 @@ func abs(num int) int {
 	return num
 }
 
-func (CGE *consoleGraphicEngine) drawTriangle(x1 int, y1 int, x2 int, y2 int, x3 int, y3 int, pix_type string, pix_color string) {
-	go CGE.drawLine(x1, y1, x2, y2, pix_type, pix_color)
-	go CGE.drawLine(x2, y2, x3, y3, pix_type, pix_color)
-	go CGE.drawLine(x3, y3, x1, y1, pix_type, pix_color)
+func minInt(first int, rest ...int) int {
+	for _, num := range rest {
+		if num < first {
+			first = num
+		}
+	}
+	return first
+}
+
+func maxInt(first int, rest ...int) int {
+	for _, num := range rest {
+		if num > first {
+			first = num
+		}
+	}
+	return first
 }
 
+// computeGraphics writes the cells that changed since the last frame to the
+// output buffer. The terminal keeps the rest, so a still scene costs
+// nothing and a moving one only its moving parts.
 func (CGE *consoleGraphicEngine) computeGraphics() {
-	var out string = "\033[0;0H"
+	// Where the terminal cursor is, -1 when unknown
+	cursorX, cursorY := -1, -1
+	changed := false
+
 	for index, pix := range CGE.pixels {
-		out += pix.color + pix.pixel_type
-		if (index+1)%CGE.screenWidth == 0 {
-			out += "\n"
+		if pix == CGE.previous[index] {
+			continue
+		}
+		x, y := index%CGE.screenWidth, index/CGE.screenWidth
+		if x != cursorX || y != cursorY {
+			fmt.Fprintf(CGE.output, "\033[%d;%dH", y+1, x+1)
+		}
+		// Colors stay set across cells and frames. Colors that map to the
+		// same terminal color share an escape and are skipped too.
+		if pix.color != CGE.outputColor || CGE.colorEscape == "" {
+			if escape := CGE.cachedEscape(pix.color, false); escape != CGE.colorEscape {
+				CGE.output.WriteString(escape)
+				CGE.colorEscape = escape
+			}
+			CGE.outputColor = pix.color
+		}
+		if pix.background != CGE.outputBack || CGE.backEscape == "" {
+			if escape := CGE.cachedEscape(pix.background, true); escape != CGE.backEscape {
+				CGE.output.WriteString(escape)
+				CGE.backEscape = escape
+			}
+			CGE.outputBack = pix.background
+		}
+		CGE.output.WriteString(pix.pixel_type)
+		CGE.previous[index] = pix
+		changed = true
+
+		cursorX, cursorY = x+1, y
+		// Writing the last column leaves the cursor waiting to wrap
+		if cursorX == CGE.screenWidth {
+			cursorX, cursorY = -1, -1
 		}
 	}
-	out += "\n"
-	CGE.output = out
+
+	// Park the cursor below the frame, out of the way of other output
+	if changed {
+		fmt.Fprintf(CGE.output, "\033[%d;1H", CGE.screenHeight+1)
+	}
 }
 
 func (CGE *consoleGraphicEngine) render() {
-	fmt.Print(CGE.output)
+	CGE.output.Flush()
 }
 
 func (CGE *consoleGraphicEngine) Start() {
-	CGE.component.onCreate()
+	for _, component := range CGE.components {
+		component.onCreate()
+	}
 
 	for {
-		CGE.component.onUpdate()
+		CGE.drawFrame()
 		CGE.computeGraphics()
 		CGE.render()
 	}
 
 }
 
+// drawFrame clears the screen, lets every component queue its draws, then
+// rasterizes them. The pixels are complete when it returns.
+func (CGE *consoleGraphicEngine) drawFrame() {
+	CGE.fillALL(CGE.background.pixel_type, CGE.background.color, CGE.background.background)
+	for _, component := range CGE.components {
+		component.onUpdate()
+	}
+	CGE.rasterize()
+}
+
+// Components update, and so draw, in the order they were added.
 func (CGE *consoleGraphicEngine) addComponent(new consoleComponent) {
-	CGE.component = new
+	CGE.components = append(CGE.components, new)
 }
 
 // func main() {
-// 	conRen := constructConsoleGraphicEngine(300, 100, WHITE)
+// 	conRen := constructConsoleGraphicEngine(300, 100, WHITE, BLACK, COLOR_AUTO)
 
 // 	conRen.computeGraphics()
 // 	conRen.render()
 
 // 	conRen.drawTriangle(3, 3, 250, 3, 3, 99, FULL_BLOCK, RED)
+// 	conRen.rasterize()
 
 // 	conRen.computeGraphics()
 // 	conRen.render()
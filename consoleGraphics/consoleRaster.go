@@ -0,0 +1,166 @@
+package consoleGraphics
+
+import (
+	"math"
+	"sync"
+)
+
+// Rasterizing is split into screen tiles, each owned by one worker for the
+// whole frame. Every tile replays the commands in queue order, so a cell is
+// only ever written by one goroutine and overlapping draws always resolve the
+// same way, however the tiles are scheduled.
+
+const (
+	TILE_WIDTH  = 32
+	TILE_HEIGHT = 16
+)
+
+type drawCommandType int
+
+const (
+	FILL_COMMAND drawCommandType = iota
+	PIXEL_COMMAND
+	LINE_COMMAND
+	TRIANGLE_COMMAND
+	FILL_TRIANGLE_COMMAND
+)
+
+type drawCommand struct {
+	kind drawCommandType
+	pix  pixel
+	x    [3]int // vertices, as many as the kind uses
+	y    [3]int
+	// FILL_TRIANGLE_COMMAND vertices, with depth
+	vx, vy, vz [3]float32
+	// Bounding box, to skip tiles the command cannot touch
+	minX, minY, maxX, maxY int
+}
+
+// tile is an inclusive rectangle of cells.
+type tile struct {
+	minX, minY, maxX, maxY int
+}
+
+func splitTiles(width int, height int) []tile {
+	var tiles []tile
+	for y := 0; y < height; y += TILE_HEIGHT {
+		for x := 0; x < width; x += TILE_WIDTH {
+			tiles = append(tiles, tile{x, y, minInt(x+TILE_WIDTH, width) - 1, minInt(y+TILE_HEIGHT, height) - 1})
+		}
+	}
+	return tiles
+}
+
+func (t tile) overlaps(cmd *drawCommand) bool {
+	return cmd.maxX >= t.minX && cmd.minX <= t.maxX && cmd.maxY >= t.minY && cmd.minY <= t.maxY
+}
+
+func (CGE *consoleGraphicEngine) queue(cmd drawCommand) {
+	CGE.commands = append(CGE.commands, cmd)
+}
+
+// rasterize runs the queued commands on a pool of CGE.workers goroutines and
+// waits for all of them, then empties the queue.
+func (CGE *consoleGraphicEngine) rasterize() {
+	tileIndices := make(chan int, len(CGE.tiles))
+	for index := range CGE.tiles {
+		tileIndices <- index
+	}
+	close(tileIndices)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < minInt(CGE.workers, len(CGE.tiles)); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range tileIndices {
+				CGE.rasterizeTile(CGE.tiles[index])
+			}
+		}()
+	}
+	wg.Wait()
+
+	CGE.commands = CGE.commands[:0]
+}
+
+func (CGE *consoleGraphicEngine) rasterizeTile(t tile) {
+	for y := t.minY; y <= t.maxY; y++ {
+		for x := t.minX; x <= t.maxX; x++ {
+			CGE.depth[y*CGE.screenWidth+x] = math.MaxFloat32
+		}
+	}
+
+	for index := range CGE.commands {
+		cmd := &CGE.commands[index]
+		if !t.overlaps(cmd) {
+			continue
+		}
+
+		plot := func(x int, y int) {
+			if x >= t.minX && x <= t.maxX && y >= t.minY && y <= t.maxY {
+				CGE.drawCell(y*CGE.screenWidth+x, cmd)
+			}
+		}
+
+		switch cmd.kind {
+		case FILL_COMMAND:
+			for y := maxInt(cmd.minY, t.minY); y <= minInt(cmd.maxY, t.maxY); y++ {
+				for x := maxInt(cmd.minX, t.minX); x <= minInt(cmd.maxX, t.maxX); x++ {
+					CGE.pixels[y*CGE.screenWidth+x] = cmd.pix
+				}
+			}
+		case PIXEL_COMMAND:
+			plot(cmd.x[0], cmd.y[0])
+		case LINE_COMMAND:
+			rasterLine(cmd.x[0], cmd.y[0], cmd.x[1], cmd.y[1], t, plot)
+		case TRIANGLE_COMMAND:
+			rasterLine(cmd.x[0], cmd.y[0], cmd.x[1], cmd.y[1], t, plot)
+			rasterLine(cmd.x[1], cmd.y[1], cmd.x[2], cmd.y[2], t, plot)
+			rasterLine(cmd.x[2], cmd.y[2], cmd.x[0], cmd.y[0], t, plot)
+		case FILL_TRIANGLE_COMMAND:
+			CGE.rasterFilledTriangle(cmd, t)
+		}
+	}
+}
+
+// drawCell sets the glyph and color, keeping the cell's background.
+func (CGE *consoleGraphicEngine) drawCell(target int, cmd *drawCommand) {
+	CGE.pixels[target].pixel_type = cmd.pix.pixel_type
+	CGE.pixels[target].color = cmd.pix.color
+}
+
+// edgeFunction is twice the signed area of (a, b, p): positive on one side
+// of a -> b, negative on the other, zero on the line.
+func edgeFunction(ax, ay, bx, by, px, py float32) float32 {
+	return (bx-ax)*(py-ay) - (by-ay)*(px-ax)
+}
+
+// rasterFilledTriangle tests the centre of every cell in the triangle's
+// bounding box, within t, against the three edges. Either winding fills.
+func (CGE *consoleGraphicEngine) rasterFilledTriangle(cmd *drawCommand, t tile) {
+	x, y, z := cmd.vx, cmd.vy, cmd.vz
+	area := edgeFunction(x[0], y[0], x[1], y[1], x[2], y[2])
+	if area == 0 {
+		return
+	}
+
+	for cy := maxInt(cmd.minY, t.minY); cy <= minInt(cmd.maxY, t.maxY); cy++ {
+		for cx := maxInt(cmd.minX, t.minX); cx <= minInt(cmd.maxX, t.maxX); cx++ {
+			px, py := float32(cx)+0.5, float32(cy)+0.5
+			// Barycentric weights, all positive inside
+			w0 := edgeFunction(x[1], y[1], x[2], y[2], px, py) / area
+			w1 := edgeFunction(x[2], y[2], x[0], y[0], px, py) / area
+			w2 := edgeFunction(x[0], y[0], x[1], y[1], px, py) / area
+			if w0 < 0 || w1 < 0 || w2 < 0 {
+				continue
+			}
+
+			depth := w0*z[0] + w1*z[1] + w2*z[2]
+			target := cy*CGE.screenWidth + cx
+			if depth < CGE.depth[target] {
+				CGE.depth[target] = depth
+				CGE.drawCell(target, cmd)
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package consoleGraphics
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/Trip1eLift/3d-engine-go/graphicsMath"
+)
+
+// sceneComponent queues overlapping fills, lines and filled triangles that
+// span several tiles, the same commands every frame.
+type sceneComponent struct {
+	container *consoleGraphicEngine
+	updates   int
+}
+
+func (s *sceneComponent) onCreate() bool {
+	return true
+}
+
+func (s *sceneComponent) onUpdate() bool {
+	CGE := s.container
+	CGE.fillRect(10, 5, 70, 30, SPACE_BLOCK, WHITE, BLUE)
+	// Near first, so the far triangle has to lose the depth test
+	CGE.fillTriangle(20, 10, 0.2, 60, 10, 0.2, 20, 40, 0.2, FULL_BLOCK, GREEN)
+	CGE.fillTriangle(30, 15, 0.8, 80, 15, 0.8, 80, 45, 0.8, DARK_BLOCK, RED)
+	// Endpoints far off screen
+	CGE.drawLine(-1000000, -1000000, 1000000, 1000000, MEDIUM_BLOCK, PURPLE)
+	CGE.drawLine(-1000000, 24, 1000000, 24, LIGHT_BLOCK, YELLOW)
+	CGE.drawTriangle(85, 2, 94, 2, 94, 10, FULL_BLOCK, CYAN)
+	s.updates++
+	return true
+}
+
+func renderScene(t *testing.T, workers int) []pixel {
+	t.Helper()
+	CGE := constructConsoleGraphicEngine(96, 48, WHITE, BLACK, COLOR_TRUECOLOR)
+	CGE.workers = workers
+	scene := &sceneComponent{container: CGE}
+	CGE.addComponent(scene)
+	for frame := 0; frame < 3; frame++ {
+		CGE.drawFrame()
+	}
+	if scene.updates != 3 {
+		t.Fatalf("updates = %d, want 3", scene.updates)
+	}
+	if len(CGE.commands) != 0 {
+		t.Fatalf("%d commands left queued", len(CGE.commands))
+	}
+	return CGE.pixels
+}
+
+func TestRasterizeScene(t *testing.T) {
+	const width = 96
+	pixels := renderScene(t, 8)
+	at := func(x int, y int) pixel {
+		return pixels[y*width+x]
+	}
+
+	cases := []struct {
+		name string
+		x, y int
+		want pixel
+	}{
+		{"background", 5, 2, pixel{SPACE_BLOCK, WHITE, BLACK}},
+		{"fill", 12, 6, pixel{SPACE_BLOCK, WHITE, BLUE}},
+		{"near over far", 35, 16, pixel{FULL_BLOCK, GREEN, BLUE}},
+		{"far alone", 75, 20, pixel{DARK_BLOCK, RED, BLACK}},
+		{"diagonal over fill", 15, 15, pixel{MEDIUM_BLOCK, PURPLE, BLUE}},
+		{"diagonal last row", 47, 47, pixel{MEDIUM_BLOCK, PURPLE, BLACK}},
+		{"wireframe corner", 85, 2, pixel{FULL_BLOCK, CYAN, BLACK}},
+		{"wireframe edge", 94, 6, pixel{FULL_BLOCK, CYAN, BLACK}},
+		{"wireframe inside", 92, 3, pixel{SPACE_BLOCK, WHITE, BLACK}},
+	}
+	for _, c := range cases {
+		if got := at(c.x, c.y); got != c.want {
+			t.Errorf("%s (%d, %d) = %+v, want %+v", c.name, c.x, c.y, got, c.want)
+		}
+	}
+
+	// The horizontal line is queued last and crosses every tile of its row
+	for x := 0; x < width; x++ {
+		if got := at(x, 24); got.pixel_type != LIGHT_BLOCK || got.color != YELLOW {
+			t.Fatalf("row 24 column %d = %+v, want the yellow line", x, got)
+		}
+	}
+}
+
+func TestRasterizeWorkersAgree(t *testing.T) {
+	reference := renderScene(t, 1)
+	for _, workers := range []int{2, 4, 16} {
+		pixels := renderScene(t, workers)
+		for index := range reference {
+			if pixels[index] != reference[index] {
+				t.Fatalf("%d workers: cell %d = %+v, one worker drew %+v", workers, index, pixels[index], reference[index])
+			}
+		}
+	}
+}
+
+func TestFillTriangleCoversScreen(t *testing.T) {
+	const width, height = 70, 20
+	CGE := constructConsoleGraphicEngine(width, height, WHITE, BLACK, COLOR_TRUECOLOR)
+	huge := graphicsMath.Triangle{P: [3]graphicsMath.Vec3{
+		{X: -1000, Y: -1000, Z: 0.5}, {X: 3000, Y: -1000, Z: 0.5}, {X: -1000, Y: 3000, Z: 0.5},
+	}}
+	// Clipped the way the cube clips its faces
+	for _, tri := range huge.ClipToRect(0, 0, width, height) {
+		CGE.fillTriangle(tri.P[0].X, tri.P[0].Y, tri.P[0].Z, tri.P[1].X, tri.P[1].Y, tri.P[1].Z,
+			tri.P[2].X, tri.P[2].Y, tri.P[2].Z, FULL_BLOCK, GREEN)
+	}
+	CGE.rasterize()
+
+	for index, pix := range CGE.pixels {
+		if pix.pixel_type != FULL_BLOCK || pix.color != GREEN {
+			t.Fatalf("cell (%d, %d) = %+v, want filled", index%width, index/width, pix)
+		}
+	}
+}
+
+func TestFillTriangleKeepsNearest(t *testing.T) {
+	near := func(CGE *consoleGraphicEngine) {
+		CGE.fillTriangle(0, 0, 0.25, 40, 0, 0.25, 0, 20, 0.25, FULL_BLOCK, GREEN)
+	}
+	far := func(CGE *consoleGraphicEngine) {
+		// Covers near entirely, and slopes so it is never nearer
+		CGE.fillTriangle(-40, -40, 0.5, 120, -40, 0.5, -40, 120, 0.9, DARK_BLOCK, RED)
+	}
+
+	for _, order := range []struct {
+		name   string
+		queued [2]func(*consoleGraphicEngine)
+	}{
+		{"near first", [2]func(*consoleGraphicEngine){near, far}},
+		{"far first", [2]func(*consoleGraphicEngine){far, near}},
+	} {
+		CGE := constructConsoleGraphicEngine(40, 20, WHITE, BLACK, COLOR_TRUECOLOR)
+		order.queued[0](CGE)
+		order.queued[1](CGE)
+		CGE.rasterize()
+
+		if got := CGE.pixels[5*40+5]; got.pixel_type != FULL_BLOCK || got.color != GREEN {
+			t.Errorf("%s: overlap = %+v, want the near triangle", order.name, got)
+		}
+		if got := CGE.pixels[19*40+39]; got.pixel_type != DARK_BLOCK || got.color != RED {
+			t.Errorf("%s: far only = %+v, want the far triangle", order.name, got)
+		}
+	}
+}
+
+// bresenhamLine is the whole, unclipped walk rasterLine must reproduce.
+func bresenhamLine(x1 int, y1 int, x2 int, y2 int, plot func(x int, y int)) {
+	dx, dy := x2-x1, y2-y1
+	dx1, dy1 := abs(dx), abs(dy)
+	step := -1
+	if (dx < 0 && dy < 0) || (dx > 0 && dy > 0) {
+		step = 1
+	}
+	if dy1 <= dx1 {
+		x, y, xe := x1, y1, x2
+		if dx < 0 {
+			x, y, xe = x2, y2, x1
+		}
+		plot(x, y)
+		for px := 2*dy1 - dx1; x < xe; {
+			x++
+			if px < 0 {
+				px += 2 * dy1
+			} else {
+				y += step
+				px += 2 * (dy1 - dx1)
+			}
+			plot(x, y)
+		}
+	} else {
+		x, y, ye := x1, y1, y2
+		if dy < 0 {
+			x, y, ye = x2, y2, y1
+		}
+		plot(x, y)
+		for py := 2*dx1 - dy1; y < ye; {
+			y++
+			if py <= 0 {
+				py += 2 * dx1
+			} else {
+				x += step
+				py += 2 * (dx1 - dy1)
+			}
+			plot(x, y)
+		}
+	}
+}
+
+func TestRasterLineClipsToTile(t *testing.T) {
+	type cell struct{ x, y int }
+	tiles := append(splitTiles(100, 40), tile{-5, -5, 104, 44})
+	random := rand.New(rand.NewSource(1))
+	coordinate := func() int {
+		return random.Intn(140) - 20
+	}
+
+	for line := 0; line < 2000; line++ {
+		x1, y1, x2, y2 := coordinate(), coordinate(), coordinate(), coordinate()
+		var whole []cell
+		bresenhamLine(x1, y1, x2, y2, func(x int, y int) {
+			whole = append(whole, cell{x, y})
+		})
+
+		for _, clip := range tiles {
+			var want, got []cell
+			for _, c := range whole {
+				if c.x >= clip.minX && c.x <= clip.maxX && c.y >= clip.minY && c.y <= clip.maxY {
+					want = append(want, c)
+				}
+			}
+			rasterLine(x1, y1, x2, y2, clip, func(x int, y int) {
+				got = append(got, cell{x, y})
+			})
+			if len(got) != len(want) {
+				t.Fatalf("(%d, %d) to (%d, %d) in %+v: %d cells, want %d", x1, y1, x2, y2, clip, len(got), len(want))
+			}
+			for index := range want {
+				if got[index] != want[index] {
+					t.Fatalf("(%d, %d) to (%d, %d) in %+v: cell %d = %v, want %v", x1, y1, x2, y2, clip, index, got[index], want[index])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package graphicsMath
+
+// Camera is a perspective viewpoint. With an identity Orientation it looks
+// down +z with +y up.
+type Camera struct {
+	Position    Vec3
+	Orientation Quat
+	FovDeg      float32
+	Near        float32
+	Far         float32
+	AspectRatio float32 // height / width, as MakePerspective expects
+}
+
+func NewCamera(fAspectRatio float32) *Camera {
+	return &Camera{
+		Orientation: QuatIdentity(),
+		FovDeg:      90.0,
+		Near:        0.1,
+		Far:         1000.0,
+		AspectRatio: fAspectRatio,
+	}
+}
+
+func (c *Camera) Forward() Vec3 {
+	return c.Orientation.Rotate(Vec3{0, 0, 1})
+}
+
+func (c *Camera) Right() Vec3 {
+	return c.Orientation.Rotate(Vec3{1, 0, 0})
+}
+
+func (c *Camera) Up() Vec3 {
+	return c.Orientation.Rotate(Vec3{0, 1, 0})
+}
+
+// ViewMatrix takes world space to view space, the inverse of the camera's
+// own placement.
+func (c *Camera) ViewMatrix() Mat4 {
+	return MakeTranslation(-c.Position.X, -c.Position.Y, -c.Position.Z).
+		Mul(c.Orientation.Conjugate().Mat4())
+}
+
+// ProjectionMatrix has depth in [0, 1], for the CPU clipper.
+func (c *Camera) ProjectionMatrix() Mat4 {
+	return MakePerspective(c.FovDeg, c.AspectRatio, c.Near, c.Far)
+}
+
+// ProjectionMatrixGL has depth in [-1, 1], for shaders, so OpenGL clips at
+// Near and Far.
+func (c *Camera) ProjectionMatrixGL() Mat4 {
+	return MakePerspectiveGL(c.FovDeg, c.AspectRatio, c.Near, c.Far)
+}
@@ -0,0 +1,73 @@
+package graphicsMath
+
+// IntersectPlane returns where the segment lineStart -> lineEnd crosses the
+// plane through planeP with normal planeN, and how far along the segment
+// (0 at lineStart, 1 at lineEnd) that point lies.
+func IntersectPlane(planeP, planeN, lineStart, lineEnd Vec3) (Vec3, float32) {
+	planeN = planeN.Normalize()
+	planeD := -planeN.Dot(planeP)
+	ad := lineStart.Dot(planeN)
+	bd := lineEnd.Dot(planeN)
+	t := (-planeD - ad) / (bd - ad)
+	return lineStart.Add(lineEnd.Sub(lineStart).Scale(t)), t
+}
+
+// ClipAgainstPlane keeps the part of t on the side planeN points to. The
+// result holds 0, 1 or 2 triangles and keeps the winding of t.
+func (t Triangle) ClipAgainstPlane(planeP, planeN Vec3) []Triangle {
+	planeN = planeN.Normalize()
+	dist := func(p Vec3) float32 {
+		return planeN.Dot(p) - planeN.Dot(planeP)
+	}
+
+	// Sutherland-Hodgman on a single triangle gives at most 4 vertices
+	var poly [4]Vec3
+	var uv [4]Vec2
+	n := 0
+	for i := 0; i < 3; i++ {
+		j := (i + 1) % 3
+		curInside, nextInside := dist(t.P[i]) >= 0, dist(t.P[j]) >= 0
+		if curInside {
+			poly[n], uv[n] = t.P[i], t.T[i]
+			n++
+		}
+		if curInside != nextInside {
+			var s float32
+			poly[n], s = IntersectPlane(planeP, planeN, t.P[i], t.P[j])
+			uv[n] = t.T[i].Lerp(t.T[j], s)
+			n++
+		}
+	}
+
+	switch n {
+	case 3:
+		return []Triangle{{P: [3]Vec3{poly[0], poly[1], poly[2]}, T: [3]Vec2{uv[0], uv[1], uv[2]}}}
+	case 4:
+		return []Triangle{
+			{P: [3]Vec3{poly[0], poly[1], poly[2]}, T: [3]Vec2{uv[0], uv[1], uv[2]}},
+			{P: [3]Vec3{poly[0], poly[2], poly[3]}, T: [3]Vec2{uv[0], uv[2], uv[3]}},
+		}
+	}
+	return nil
+}
+
+// ClipToRect clips t against the four edges of the rectangle
+// (minX, minY) - (maxX, maxY) in the XY plane.
+func (t Triangle) ClipToRect(minX, minY, maxX, maxY float32) []Triangle {
+	planes := [4][2]Vec3{
+		{{0, minY, 0}, {0, 1, 0}},
+		{{0, maxY, 0}, {0, -1, 0}},
+		{{minX, 0, 0}, {1, 0, 0}},
+		{{maxX, 0, 0}, {-1, 0, 0}},
+	}
+
+	tris := []Triangle{t}
+	for _, plane := range planes {
+		var clipped []Triangle
+		for _, tri := range tris {
+			clipped = append(clipped, tri.ClipAgainstPlane(plane[0], plane[1])...)
+		}
+		tris = clipped
+	}
+	return tris
+}
@@ -0,0 +1,115 @@
+package graphicsMath
+
+// Mat4 is a 4x4 matrix used with row vectors: a point is transformed as
+// p * M, so translation lives in row 3 and a.Mul(b) applies a first, then b.
+type Mat4 struct {
+	M [4][4]float32
+}
+
+func MultiplyMatrixVector(in Vec3, m Mat4) Vec3 {
+	var out Vec3
+	out.X = in.X*m.M[0][0] + in.Y*m.M[1][0] + in.Z*m.M[2][0] + m.M[3][0]
+	out.Y = in.X*m.M[0][1] + in.Y*m.M[1][1] + in.Z*m.M[2][1] + m.M[3][1]
+	out.Z = in.X*m.M[0][2] + in.Y*m.M[1][2] + in.Z*m.M[2][2] + m.M[3][2]
+	var w float32 = in.X*m.M[0][3] + in.Y*m.M[1][3] + in.Z*m.M[2][3] + m.M[3][3]
+
+	if w != 0.0 {
+		out.X /= w
+		out.Y /= w
+		out.Z /= w
+	}
+	return out
+}
+
+// MultiplyMatrixVector4 transforms a homogeneous vector and keeps w, so a
+// projected point stays in clip space until PerspectiveDivide is called.
+func MultiplyMatrixVector4(in Vec4, m Mat4) Vec4 {
+	var out Vec4
+	out.X = in.X*m.M[0][0] + in.Y*m.M[1][0] + in.Z*m.M[2][0] + in.W*m.M[3][0]
+	out.Y = in.X*m.M[0][1] + in.Y*m.M[1][1] + in.Z*m.M[2][1] + in.W*m.M[3][1]
+	out.Z = in.X*m.M[0][2] + in.Y*m.M[1][2] + in.Z*m.M[2][2] + in.W*m.M[3][2]
+	out.W = in.X*m.M[0][3] + in.Y*m.M[1][3] + in.Z*m.M[2][3] + in.W*m.M[3][3]
+	return out
+}
+
+func (a Mat4) Mul(b Mat4) Mat4 {
+	var out Mat4
+	for r := 0; r < 4; r++ {
+		for c := 0; c < 4; c++ {
+			out.M[r][c] = a.M[r][0]*b.M[0][c] + a.M[r][1]*b.M[1][c] + a.M[r][2]*b.M[2][c] + a.M[r][3]*b.M[3][c]
+		}
+	}
+	return out
+}
+
+func (a Mat4) Transpose() Mat4 {
+	var out Mat4
+	for r := 0; r < 4; r++ {
+		for c := 0; c < 4; c++ {
+			out.M[c][r] = a.M[r][c]
+		}
+	}
+	return out
+}
+
+func (a Mat4) Determinant() float32 {
+	m := &a.M
+	s0 := m[0][0]*m[1][1] - m[1][0]*m[0][1]
+	s1 := m[0][0]*m[1][2] - m[1][0]*m[0][2]
+	s2 := m[0][0]*m[1][3] - m[1][0]*m[0][3]
+	s3 := m[0][1]*m[1][2] - m[1][1]*m[0][2]
+	s4 := m[0][1]*m[1][3] - m[1][1]*m[0][3]
+	s5 := m[0][2]*m[1][3] - m[1][2]*m[0][3]
+	c5 := m[2][2]*m[3][3] - m[3][2]*m[2][3]
+	c4 := m[2][1]*m[3][3] - m[3][1]*m[2][3]
+	c3 := m[2][1]*m[3][2] - m[3][1]*m[2][2]
+	c2 := m[2][0]*m[3][3] - m[3][0]*m[2][3]
+	c1 := m[2][0]*m[3][2] - m[3][0]*m[2][2]
+	c0 := m[2][0]*m[3][1] - m[3][0]*m[2][1]
+	return s0*c5 - s1*c4 + s2*c3 + s3*c2 - s4*c1 + s5*c0
+}
+
+// Inverse returns the inverse of a. ok is false when a is singular, in which
+// case the zero matrix is returned.
+func (a Mat4) Inverse() (inv Mat4, ok bool) {
+	m := &a.M
+	s0 := m[0][0]*m[1][1] - m[1][0]*m[0][1]
+	s1 := m[0][0]*m[1][2] - m[1][0]*m[0][2]
+	s2 := m[0][0]*m[1][3] - m[1][0]*m[0][3]
+	s3 := m[0][1]*m[1][2] - m[1][1]*m[0][2]
+	s4 := m[0][1]*m[1][3] - m[1][1]*m[0][3]
+	s5 := m[0][2]*m[1][3] - m[1][2]*m[0][3]
+	c5 := m[2][2]*m[3][3] - m[3][2]*m[2][3]
+	c4 := m[2][1]*m[3][3] - m[3][1]*m[2][3]
+	c3 := m[2][1]*m[3][2] - m[3][1]*m[2][2]
+	c2 := m[2][0]*m[3][3] - m[3][0]*m[2][3]
+	c1 := m[2][0]*m[3][2] - m[3][0]*m[2][2]
+	c0 := m[2][0]*m[3][1] - m[3][0]*m[2][1]
+
+	det := s0*c5 - s1*c4 + s2*c3 + s3*c2 - s4*c1 + s5*c0
+	if det == 0 {
+		return Mat4{}, false
+	}
+	d := 1 / det
+
+	inv.M[0][0] = (m[1][1]*c5 - m[1][2]*c4 + m[1][3]*c3) * d
+	inv.M[0][1] = (-m[0][1]*c5 + m[0][2]*c4 - m[0][3]*c3) * d
+	inv.M[0][2] = (m[3][1]*s5 - m[3][2]*s4 + m[3][3]*s3) * d
+	inv.M[0][3] = (-m[2][1]*s5 + m[2][2]*s4 - m[2][3]*s3) * d
+
+	inv.M[1][0] = (-m[1][0]*c5 + m[1][2]*c2 - m[1][3]*c1) * d
+	inv.M[1][1] = (m[0][0]*c5 - m[0][2]*c2 + m[0][3]*c1) * d
+	inv.M[1][2] = (-m[3][0]*s5 + m[3][2]*s2 - m[3][3]*s1) * d
+	inv.M[1][3] = (m[2][0]*s5 - m[2][2]*s2 + m[2][3]*s1) * d
+
+	inv.M[2][0] = (m[1][0]*c4 - m[1][1]*c2 + m[1][3]*c0) * d
+	inv.M[2][1] = (-m[0][0]*c4 + m[0][1]*c2 - m[0][3]*c0) * d
+	inv.M[2][2] = (m[3][0]*s4 - m[3][1]*s2 + m[3][3]*s0) * d
+	inv.M[2][3] = (-m[2][0]*s4 + m[2][1]*s2 - m[2][3]*s0) * d
+
+	inv.M[3][0] = (-m[1][0]*c3 + m[1][1]*c1 - m[1][2]*c0) * d
+	inv.M[3][1] = (m[0][0]*c3 - m[0][1]*c1 + m[0][2]*c0) * d
+	inv.M[3][2] = (-m[3][0]*s3 + m[3][1]*s1 - m[3][2]*s0) * d
+	inv.M[3][3] = (m[2][0]*s3 - m[2][1]*s1 + m[2][2]*s0) * d
+	return inv, true
+}
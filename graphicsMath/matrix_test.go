@@ -0,0 +1,74 @@
+package graphicsMath
+
+import (
+	"math"
+	"testing"
+)
+
+func nearlyEqual(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-4
+}
+
+func matNearlyEqual(a, b Mat4) bool {
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			if !nearlyEqual(a.M[row][col], b.M[row][col]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestDeterminant(t *testing.T) {
+	cases := []struct {
+		name string
+		m    Mat4
+		want float32
+	}{
+		{"identity", MakeIdentity(), 1},
+		{"scale", MakeScale(2, 3, 4), 24},
+		{"translation", MakeTranslation(5, -2, 7), 1},
+		{"rotation", MakeRotationAxis(Vec3{1, 2, 3}, 0.8), 1},
+		{"triangular", Mat4{[4][4]float32{{1, 2, 3, 4}, {0, 1, 2, 3}, {0, 0, 1, 2}, {0, 0, 0, 5}}}, 5},
+		{"general", Mat4{[4][4]float32{{2, 1, 0, 3}, {0, 1, 4, 1}, {5, 0, 1, 2}, {1, 2, 3, 1}}}, -62},
+		{"repeated row", Mat4{[4][4]float32{{1, 2, 3, 4}, {1, 2, 3, 4}, {0, 1, 0, 0}, {0, 0, 0, 1}}}, 0},
+	}
+	for _, c := range cases {
+		if got := c.m.Determinant(); !nearlyEqual(got, c.want) {
+			t.Errorf("%s: Determinant() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestInverse(t *testing.T) {
+	cases := []struct {
+		name string
+		m    Mat4
+	}{
+		{"identity", MakeIdentity()},
+		{"scale", MakeScale(2, 0.5, -4)},
+		{"rigid", MakeRotationY(1.1).Mul(MakeTranslation(3, -1, 2))},
+		{"look at", MakePointAt(Vec3{1, 2, 3}, Vec3{4, -1, 7}, Vec3{0, 1, 0})},
+		{"perspective", MakePerspective(90, 0.75, 0.1, 1000)},
+		{"general", Mat4{[4][4]float32{{2, 1, 0, 3}, {0, 1, 4, 1}, {5, 0, 1, 2}, {1, 2, 3, 1}}}},
+	}
+	for _, c := range cases {
+		inv, ok := c.m.Inverse()
+		if !ok {
+			t.Errorf("%s: Inverse() reported singular", c.name)
+			continue
+		}
+		if product := inv.Mul(c.m); !matNearlyEqual(product, MakeIdentity()) {
+			t.Errorf("%s: Inverse() x M = %v, want identity", c.name, product)
+		}
+		if product := c.m.Mul(inv); !matNearlyEqual(product, MakeIdentity()) {
+			t.Errorf("%s: M x Inverse() = %v, want identity", c.name, product)
+		}
+	}
+
+	singular := Mat4{[4][4]float32{{1, 2, 3, 4}, {1, 2, 3, 4}, {0, 1, 0, 0}, {0, 0, 0, 1}}}
+	if inv, ok := singular.Inverse(); ok || inv != (Mat4{}) {
+		t.Errorf("singular: Inverse() = %v, %v, want the zero matrix, false", inv, ok)
+	}
+}
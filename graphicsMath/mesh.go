@@ -0,0 +1,110 @@
+package graphicsMath
+
+// Triangle has three vertices P and their texture coordinates T.
+type Triangle struct {
+	P [3]Vec3
+	T [3]Vec2
+}
+
+// ClipTriangle is a triangle in homogeneous clip space, before the w divide.
+type ClipTriangle struct {
+	P [3]Vec4
+	T [3]Vec2
+}
+
+type Mesh struct {
+	Tris []Triangle
+}
+
+// Normal returns the unit normal of the triangle, wound p0 -> p1 -> p2.
+func (t Triangle) Normal() Vec3 {
+	line1 := t.P[1].Sub(t.P[0])
+	line2 := t.P[2].Sub(t.P[0])
+	return line1.Cross(line2).Normalize()
+}
+
+// Transform returns the triangle with every vertex multiplied by m.
+func (t Triangle) Transform(m Mat4) Triangle {
+	out := Triangle{T: t.T}
+	out.P[0] = MultiplyMatrixVector(t.P[0], m)
+	out.P[1] = MultiplyMatrixVector(t.P[1], m)
+	out.P[2] = MultiplyMatrixVector(t.P[2], m)
+	return out
+}
+
+// Project transforms the triangle into clip space, keeping w.
+func (t Triangle) Project(m Mat4) ClipTriangle {
+	out := ClipTriangle{T: t.T}
+	out.P[0] = MultiplyMatrixVector4(t.P[0].Vec4(1), m)
+	out.P[1] = MultiplyMatrixVector4(t.P[1].Vec4(1), m)
+	out.P[2] = MultiplyMatrixVector4(t.P[2].Vec4(1), m)
+	return out
+}
+
+// PerspectiveDivide maps the triangle to normalized device coordinates.
+func (t ClipTriangle) PerspectiveDivide() Triangle {
+	out := Triangle{T: t.T}
+	out.P[0] = t.P[0].PerspectiveDivide()
+	out.P[1] = t.P[1].PerspectiveDivide()
+	out.P[2] = t.P[2].PerspectiveDivide()
+	return out
+}
+
+// UnitCube returns the 12 triangles of a cube spanning (0,0,0)-(1,1,1),
+// wound clockwise when seen from outside. Every face maps the whole texture.
+func UnitCube() Mesh {
+	var meshCube Mesh
+	var tri Triangle
+	uvFirst := [3]Vec2{{0, 0}, {0, 1}, {1, 1}}
+	uvSecond := [3]Vec2{{0, 0}, {1, 1}, {1, 0}}
+
+	// SOUTH
+	tri.P[0], tri.P[1], tri.P[2] = Vec3{0.0, 0.0, 0.0}, Vec3{0.0, 1.0, 0.0}, Vec3{1.0, 1.0, 0.0}
+	tri.T = uvFirst
+	meshCube.Tris = append(meshCube.Tris, tri)
+	tri.P[0], tri.P[1], tri.P[2] = Vec3{0.0, 0.0, 0.0}, Vec3{1.0, 1.0, 0.0}, Vec3{1.0, 0.0, 0.0}
+	tri.T = uvSecond
+	meshCube.Tris = append(meshCube.Tris, tri)
+
+	// EAST
+	tri.P[0], tri.P[1], tri.P[2] = Vec3{1.0, 0.0, 0.0}, Vec3{1.0, 1.0, 0.0}, Vec3{1.0, 1.0, 1.0}
+	tri.T = uvFirst
+	meshCube.Tris = append(meshCube.Tris, tri)
+	tri.P[0], tri.P[1], tri.P[2] = Vec3{1.0, 0.0, 0.0}, Vec3{1.0, 1.0, 1.0}, Vec3{1.0, 0.0, 1.0}
+	tri.T = uvSecond
+	meshCube.Tris = append(meshCube.Tris, tri)
+
+	// NORTH
+	tri.P[0], tri.P[1], tri.P[2] = Vec3{1.0, 0.0, 1.0}, Vec3{1.0, 1.0, 1.0}, Vec3{0.0, 1.0, 1.0}
+	tri.T = uvFirst
+	meshCube.Tris = append(meshCube.Tris, tri)
+	tri.P[0], tri.P[1], tri.P[2] = Vec3{1.0, 0.0, 1.0}, Vec3{0.0, 1.0, 1.0}, Vec3{0.0, 0.0, 1.0}
+	tri.T = uvSecond
+	meshCube.Tris = append(meshCube.Tris, tri)
+
+	// WEST
+	tri.P[0], tri.P[1], tri.P[2] = Vec3{0.0, 0.0, 1.0}, Vec3{0.0, 1.0, 1.0}, Vec3{0.0, 1.0, 0.0}
+	tri.T = uvFirst
+	meshCube.Tris = append(meshCube.Tris, tri)
+	tri.P[0], tri.P[1], tri.P[2] = Vec3{0.0, 0.0, 1.0}, Vec3{0.0, 1.0, 0.0}, Vec3{0.0, 0.0, 0.0}
+	tri.T = uvSecond
+	meshCube.Tris = append(meshCube.Tris, tri)
+
+	// TOP
+	tri.P[0], tri.P[1], tri.P[2] = Vec3{0.0, 1.0, 0.0}, Vec3{0.0, 1.0, 1.0}, Vec3{1.0, 1.0, 1.0}
+	tri.T = uvFirst
+	meshCube.Tris = append(meshCube.Tris, tri)
+	tri.P[0], tri.P[1], tri.P[2] = Vec3{0.0, 1.0, 0.0}, Vec3{1.0, 1.0, 1.0}, Vec3{1.0, 1.0, 0.0}
+	tri.T = uvSecond
+	meshCube.Tris = append(meshCube.Tris, tri)
+
+	// BOTTOM
+	tri.P[0], tri.P[1], tri.P[2] = Vec3{1.0, 0.0, 1.0}, Vec3{0.0, 0.0, 1.0}, Vec3{0.0, 0.0, 0.0}
+	tri.T = uvFirst
+	meshCube.Tris = append(meshCube.Tris, tri)
+	tri.P[0], tri.P[1], tri.P[2] = Vec3{1.0, 0.0, 1.0}, Vec3{0.0, 0.0, 0.0}, Vec3{1.0, 0.0, 0.0}
+	tri.T = uvSecond
+	meshCube.Tris = append(meshCube.Tris, tri)
+
+	return meshCube
+}
@@ -0,0 +1,129 @@
+package graphicsMath
+
+import "math"
+
+// Quat is a rotation quaternion, W + Xi + Yj + Zk. Like Mat4, q.Mul(r)
+// applies q first, then r.
+type Quat struct {
+	W, X, Y, Z float32
+}
+
+func QuatIdentity() Quat {
+	return Quat{1, 0, 0, 0}
+}
+
+// QuatFromAxisAngle agrees with MakeRotationAxis.
+func QuatFromAxisAngle(axis Vec3, fAngleRad float32) Quat {
+	a := axis.Normalize()
+	c, s := cosSin(fAngleRad * 0.5)
+	return Quat{c, a.X * s, a.Y * s, a.Z * s}
+}
+
+// QuatFromEuler rolls about Z, then pitches about X, then yaws about Y.
+func QuatFromEuler(fPitchRad, fYawRad, fRollRad float32) Quat {
+	roll := QuatFromAxisAngle(Vec3{0, 0, 1}, fRollRad)
+	pitch := QuatFromAxisAngle(Vec3{1, 0, 0}, fPitchRad)
+	yaw := QuatFromAxisAngle(Vec3{0, 1, 0}, fYawRad)
+	return roll.Mul(pitch).Mul(yaw)
+}
+
+func (q Quat) Mul(r Quat) Quat {
+	// Hamilton product r * q, so q is applied first
+	return Quat{
+		r.W*q.W - r.X*q.X - r.Y*q.Y - r.Z*q.Z,
+		r.W*q.X + r.X*q.W + r.Y*q.Z - r.Z*q.Y,
+		r.W*q.Y - r.X*q.Z + r.Y*q.W + r.Z*q.X,
+		r.W*q.Z + r.X*q.Y - r.Y*q.X + r.Z*q.W,
+	}
+}
+
+func (q Quat) Dot(r Quat) float32 {
+	return q.W*r.W + q.X*r.X + q.Y*r.Y + q.Z*r.Z
+}
+
+func (q Quat) Length() float32 {
+	return float32(math.Sqrt(float64(q.Dot(q))))
+}
+
+func (q Quat) Normalize() Quat {
+	l := q.Length()
+	if l == 0 {
+		return QuatIdentity()
+	}
+	return Quat{q.W / l, q.X / l, q.Y / l, q.Z / l}
+}
+
+func (q Quat) Conjugate() Quat {
+	return Quat{q.W, -q.X, -q.Y, -q.Z}
+}
+
+func (q Quat) Inverse() Quat {
+	d := q.Dot(q)
+	if d == 0 {
+		return QuatIdentity()
+	}
+	return Quat{q.W / d, -q.X / d, -q.Y / d, -q.Z / d}
+}
+
+// Rotate applies the rotation to v.
+func (q Quat) Rotate(v Vec3) Vec3 {
+	u := Vec3{q.X, q.Y, q.Z}
+	t := u.Cross(v).Scale(2)
+	return v.Add(t.Scale(q.W)).Add(u.Cross(t))
+}
+
+// Mat4 converts a unit quaternion to a rotation matrix.
+func (q Quat) Mat4() Mat4 {
+	xx, yy, zz := q.X*q.X, q.Y*q.Y, q.Z*q.Z
+	xy, xz, yz := q.X*q.Y, q.X*q.Z, q.Y*q.Z
+	wx, wy, wz := q.W*q.X, q.W*q.Y, q.W*q.Z
+
+	m := MakeIdentity()
+	m.M[0][0] = 1 - 2*(yy+zz)
+	m.M[0][1] = 2 * (xy + wz)
+	m.M[0][2] = 2 * (xz - wy)
+	m.M[1][0] = 2 * (xy - wz)
+	m.M[1][1] = 1 - 2*(xx+zz)
+	m.M[1][2] = 2 * (yz + wx)
+	m.M[2][0] = 2 * (xz + wy)
+	m.M[2][1] = 2 * (yz - wx)
+	m.M[2][2] = 1 - 2*(xx+yy)
+	return m
+}
+
+// Nlerp linearly blends a to b along the shortest path and renormalizes.
+func Nlerp(a, b Quat, t float32) Quat {
+	if a.Dot(b) < 0 {
+		b = Quat{-b.W, -b.X, -b.Y, -b.Z}
+	}
+	return Quat{
+		a.W + (b.W-a.W)*t,
+		a.X + (b.X-a.X)*t,
+		a.Y + (b.Y-a.Y)*t,
+		a.Z + (b.Z-a.Z)*t,
+	}.Normalize()
+}
+
+// Slerp interpolates a to b at constant angular speed along the shortest path.
+func Slerp(a, b Quat, t float32) Quat {
+	cosTheta := a.Dot(b)
+	if cosTheta < 0 {
+		b = Quat{-b.W, -b.X, -b.Y, -b.Z}
+		cosTheta = -cosTheta
+	}
+	// Nearly parallel, avoid dividing by sin(theta) ~ 0
+	if cosTheta > 0.9995 {
+		return Nlerp(a, b, t)
+	}
+
+	theta := math.Acos(float64(cosTheta))
+	sinTheta := math.Sin(theta)
+	wa := float32(math.Sin((1-float64(t))*theta) / sinTheta)
+	wb := float32(math.Sin(float64(t)*theta) / sinTheta)
+	return Quat{
+		a.W*wa + b.W*wb,
+		a.X*wa + b.X*wb,
+		a.Y*wa + b.Y*wb,
+		a.Z*wa + b.Z*wb,
+	}
+}
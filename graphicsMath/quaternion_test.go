@@ -0,0 +1,56 @@
+package graphicsMath
+
+import "testing"
+
+func TestQuatMat4MatchesRotations(t *testing.T) {
+	cases := []struct {
+		name     string
+		axis     Vec3
+		rotation func(float32) Mat4
+	}{
+		{"x", Vec3{1, 0, 0}, MakeRotationX},
+		{"y", Vec3{0, 1, 0}, MakeRotationY},
+		{"z", Vec3{0, 0, 1}, MakeRotationZ},
+	}
+	for _, c := range cases {
+		for _, angle := range []float32{0, 0.3, -1.2, 2.5, 3.14159} {
+			got := QuatFromAxisAngle(c.axis, angle).Mat4()
+			if want := c.rotation(angle); !matNearlyEqual(got, want) {
+				t.Errorf("%s %v: Mat4() = %v, want %v", c.name, angle, got, want)
+			}
+		}
+	}
+}
+
+// quatNearlyEqual treats q and -q as equal, they are the same rotation.
+func quatNearlyEqual(a, b Quat) bool {
+	same := nearlyEqual(a.W, b.W) && nearlyEqual(a.X, b.X) && nearlyEqual(a.Y, b.Y) && nearlyEqual(a.Z, b.Z)
+	opposite := nearlyEqual(a.W, -b.W) && nearlyEqual(a.X, -b.X) && nearlyEqual(a.Y, -b.Y) && nearlyEqual(a.Z, -b.Z)
+	return same || opposite
+}
+
+func TestSlerpEndpoints(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b Quat
+	}{
+		{"quarter turn", QuatIdentity(), QuatFromAxisAngle(Vec3{0, 1, 0}, 1.5708)},
+		{"nearly parallel", QuatFromAxisAngle(Vec3{1, 0, 0}, 0.5), QuatFromAxisAngle(Vec3{1, 0, 0}, 0.51)},
+		{"opposite hemisphere", QuatFromAxisAngle(Vec3{0, 0, 1}, 0.2), QuatFromAxisAngle(Vec3{0, 0, 1}, 6)},
+		{"any axes", QuatFromEuler(0.3, -0.7, 1.1), QuatFromEuler(-1.2, 2, 0.4)},
+	}
+	for _, c := range cases {
+		if got := Slerp(c.a, c.b, 0); !quatNearlyEqual(got, c.a) {
+			t.Errorf("%s: Slerp(a, b, 0) = %v, want %v", c.name, got, c.a)
+		}
+		if got := Slerp(c.a, c.b, 1); !quatNearlyEqual(got, c.b) {
+			t.Errorf("%s: Slerp(a, b, 1) = %v, want %v", c.name, got, c.b)
+		}
+	}
+
+	// Constant angular speed, halfway is half the angle
+	half := Slerp(QuatIdentity(), QuatFromAxisAngle(Vec3{1, 0, 0}, 1.2), 0.5)
+	if want := QuatFromAxisAngle(Vec3{1, 0, 0}, 0.6); !quatNearlyEqual(half, want) {
+		t.Errorf("Slerp halfway = %v, want %v", half, want)
+	}
+}
@@ -0,0 +1,151 @@
+package graphicsMath
+
+import "math"
+
+// All constructors follow the row vector convention of Mat4 and a left
+// handed view space: +x right, +y up, +z into the screen.
+
+func MakeIdentity() Mat4 {
+	var m Mat4
+	m.M[0][0] = 1
+	m.M[1][1] = 1
+	m.M[2][2] = 1
+	m.M[3][3] = 1
+	return m
+}
+
+func MakeRotationX(fAngleRad float32) Mat4 {
+	c, s := cosSin(fAngleRad)
+	m := MakeIdentity()
+	m.M[1][1] = c
+	m.M[1][2] = s
+	m.M[2][1] = -s
+	m.M[2][2] = c
+	return m
+}
+
+func MakeRotationY(fAngleRad float32) Mat4 {
+	c, s := cosSin(fAngleRad)
+	m := MakeIdentity()
+	m.M[0][0] = c
+	m.M[0][2] = -s
+	m.M[2][0] = s
+	m.M[2][2] = c
+	return m
+}
+
+func MakeRotationZ(fAngleRad float32) Mat4 {
+	c, s := cosSin(fAngleRad)
+	m := MakeIdentity()
+	m.M[0][0] = c
+	m.M[0][1] = s
+	m.M[1][0] = -s
+	m.M[1][1] = c
+	return m
+}
+
+// MakeRotationAxis rotates about an arbitrary axis through the origin. It
+// agrees with MakeRotationX/Y/Z for the unit axes.
+func MakeRotationAxis(axis Vec3, fAngleRad float32) Mat4 {
+	a := axis.Normalize()
+	c, s := cosSin(fAngleRad)
+	t := 1 - c
+
+	m := MakeIdentity()
+	m.M[0][0] = c + a.X*a.X*t
+	m.M[0][1] = a.X*a.Y*t + a.Z*s
+	m.M[0][2] = a.X*a.Z*t - a.Y*s
+	m.M[1][0] = a.X*a.Y*t - a.Z*s
+	m.M[1][1] = c + a.Y*a.Y*t
+	m.M[1][2] = a.Y*a.Z*t + a.X*s
+	m.M[2][0] = a.X*a.Z*t + a.Y*s
+	m.M[2][1] = a.Y*a.Z*t - a.X*s
+	m.M[2][2] = c + a.Z*a.Z*t
+	return m
+}
+
+func MakeTranslation(x, y, z float32) Mat4 {
+	m := MakeIdentity()
+	m.M[3][0] = x
+	m.M[3][1] = y
+	m.M[3][2] = z
+	return m
+}
+
+func MakeScale(x, y, z float32) Mat4 {
+	m := MakeIdentity()
+	m.M[0][0] = x
+	m.M[1][1] = y
+	m.M[2][2] = z
+	return m
+}
+
+// MakePerspective builds a projection matrix. fAspectRatio is height/width
+// and depth maps to [0, 1] between fNear and fFar after the w divide.
+func MakePerspective(fFovDeg, fAspectRatio, fNear, fFar float32) Mat4 {
+	fFovRad := float32(1.0 / math.Tan(float64(fFovDeg)*0.5/180.0*math.Pi))
+
+	var m Mat4
+	m.M[0][0] = fAspectRatio * fFovRad
+	m.M[1][1] = fFovRad
+	m.M[2][2] = fFar / (fFar - fNear)
+	m.M[3][2] = (-fFar * fNear) / (fFar - fNear)
+	m.M[2][3] = 1.0
+	m.M[3][3] = 0.0
+	return m
+}
+
+// MakePerspectiveGL is MakePerspective with depth mapped to [-1, 1], the
+// clip range OpenGL uses. The CPU clipper and console renderer expect [0, 1].
+func MakePerspectiveGL(fFovDeg, fAspectRatio, fNear, fFar float32) Mat4 {
+	m := MakePerspective(fFovDeg, fAspectRatio, fNear, fFar)
+	m.M[2][2] = (fFar + fNear) / (fFar - fNear)
+	m.M[3][2] = (-2 * fFar * fNear) / (fFar - fNear)
+	return m
+}
+
+// MakeOrthographic maps the given box to x, y in [-1, 1] and z in [0, 1].
+func MakeOrthographic(left, right, bottom, top, fNear, fFar float32) Mat4 {
+	m := MakeIdentity()
+	m.M[0][0] = 2 / (right - left)
+	m.M[1][1] = 2 / (top - bottom)
+	m.M[2][2] = 1 / (fFar - fNear)
+	m.M[3][0] = -(right + left) / (right - left)
+	m.M[3][1] = -(top + bottom) / (top - bottom)
+	m.M[3][2] = -fNear / (fFar - fNear)
+	return m
+}
+
+// MakePointAt places an object at pos facing target.
+func MakePointAt(pos, target, up Vec3) Mat4 {
+	newForward := target.Sub(pos).Normalize()
+	newUp := up.Sub(newForward.Scale(up.Dot(newForward))).Normalize()
+	newRight := newUp.Cross(newForward)
+
+	var m Mat4
+	m.M[0] = [4]float32{newRight.X, newRight.Y, newRight.Z, 0}
+	m.M[1] = [4]float32{newUp.X, newUp.Y, newUp.Z, 0}
+	m.M[2] = [4]float32{newForward.X, newForward.Y, newForward.Z, 0}
+	m.M[3] = [4]float32{pos.X, pos.Y, pos.Z, 1}
+	return m
+}
+
+// MakeLookAt is the view matrix of a camera at pos looking at target, the
+// inverse of MakePointAt.
+func MakeLookAt(pos, target, up Vec3) Mat4 {
+	p := MakePointAt(pos, target, up)
+	right := Vec3{p.M[0][0], p.M[0][1], p.M[0][2]}
+	newUp := Vec3{p.M[1][0], p.M[1][1], p.M[1][2]}
+	forward := Vec3{p.M[2][0], p.M[2][1], p.M[2][2]}
+
+	var m Mat4
+	m.M[0] = [4]float32{right.X, newUp.X, forward.X, 0}
+	m.M[1] = [4]float32{right.Y, newUp.Y, forward.Y, 0}
+	m.M[2] = [4]float32{right.Z, newUp.Z, forward.Z, 0}
+	m.M[3] = [4]float32{-pos.Dot(right), -pos.Dot(newUp), -pos.Dot(forward), 1}
+	return m
+}
+
+func cosSin(fAngleRad float32) (float32, float32) {
+	return float32(math.Cos(float64(fAngleRad))), float32(math.Sin(float64(fAngleRad)))
+}
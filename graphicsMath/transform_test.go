@@ -0,0 +1,33 @@
+package graphicsMath
+
+import "testing"
+
+func TestPerspectiveDepthRange(t *testing.T) {
+	const near, far = 0.1, 1000
+	cases := []struct {
+		name       string
+		projection Mat4
+		nearDepth  float32
+		farDepth   float32
+	}{
+		{"MakePerspective", MakePerspective(90, 0.75, near, far), 0, 1},
+		{"MakePerspectiveGL", MakePerspectiveGL(90, 0.75, near, far), -1, 1},
+	}
+	for _, c := range cases {
+		for _, point := range []struct {
+			z    float32
+			want float32
+		}{{near, c.nearDepth}, {far, c.farDepth}} {
+			clip := MultiplyMatrixVector4(Vec4{0, 0, point.z, 1}, c.projection)
+			if got := clip.Z / clip.W; !nearlyEqual(got, point.want) {
+				t.Errorf("%s: depth at z = %v is %v, want %v", c.name, point.z, got, point.want)
+			}
+		}
+
+		// Only depth differs between the two
+		clip := MultiplyMatrixVector4(Vec4{1, 2, 5, 1}, c.projection)
+		if !nearlyEqual(clip.X, 0.75) || !nearlyEqual(clip.Y, 2) || !nearlyEqual(clip.W, 5) {
+			t.Errorf("%s: (1, 2, 5) projects to %v", c.name, clip)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package graphicsMath
+
+import "math"
+
+// Vec2 is a 2D point, e.g. a texture coordinate.
+type Vec2 struct {
+	X, Y float32
+}
+
+// Vec3 is a point or direction in 3D space.
+type Vec3 struct {
+	X, Y, Z float32
+}
+
+// Vec4 is a homogeneous 3D coordinate.
+type Vec4 struct {
+	X, Y, Z, W float32
+}
+
+// Lerp moves from a towards b by t.
+func (a Vec2) Lerp(b Vec2, t float32) Vec2 {
+	return Vec2{a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t}
+}
+
+// Lerp moves from a towards b by t.
+func (a Vec3) Lerp(b Vec3, t float32) Vec3 {
+	return Vec3{a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t, a.Z + (b.Z-a.Z)*t}
+}
+
+func (a Vec3) Add(b Vec3) Vec3 {
+	return Vec3{a.X + b.X, a.Y + b.Y, a.Z + b.Z}
+}
+
+func (a Vec3) Sub(b Vec3) Vec3 {
+	return Vec3{a.X - b.X, a.Y - b.Y, a.Z - b.Z}
+}
+
+func (a Vec3) Scale(k float32) Vec3 {
+	return Vec3{a.X * k, a.Y * k, a.Z * k}
+}
+
+func (a Vec3) Dot(b Vec3) float32 {
+	return a.X*b.X + a.Y*b.Y + a.Z*b.Z
+}
+
+func (a Vec3) Cross(b Vec3) Vec3 {
+	return Vec3{
+		a.Y*b.Z - a.Z*b.Y,
+		a.Z*b.X - a.X*b.Z,
+		a.X*b.Y - a.Y*b.X,
+	}
+}
+
+func (a Vec3) Length() float32 {
+	return float32(math.Sqrt(float64(a.Dot(a))))
+}
+
+// Normalize returns a unit vector, or the zero vector unchanged.
+func (a Vec3) Normalize() Vec3 {
+	l := a.Length()
+	if l == 0 {
+		return a
+	}
+	return a.Scale(1 / l)
+}
+
+// Vec4 returns a with the given w component.
+func (a Vec3) Vec4(w float32) Vec4 {
+	return Vec4{a.X, a.Y, a.Z, w}
+}
+
+func (a Vec4) Add(b Vec4) Vec4 {
+	return Vec4{a.X + b.X, a.Y + b.Y, a.Z + b.Z, a.W + b.W}
+}
+
+func (a Vec4) Sub(b Vec4) Vec4 {
+	return Vec4{a.X - b.X, a.Y - b.Y, a.Z - b.Z, a.W - b.W}
+}
+
+func (a Vec4) Scale(k float32) Vec4 {
+	return Vec4{a.X * k, a.Y * k, a.Z * k, a.W * k}
+}
+
+func (a Vec4) Dot(b Vec4) float32 {
+	return a.X*b.X + a.Y*b.Y + a.Z*b.Z + a.W*b.W
+}
+
+func (a Vec4) Length() float32 {
+	return float32(math.Sqrt(float64(a.Dot(a))))
+}
+
+func (a Vec4) Normalize() Vec4 {
+	l := a.Length()
+	if l == 0 {
+		return a
+	}
+	return a.Scale(1 / l)
+}
+
+// Vec3 drops the w component without dividing by it.
+func (a Vec4) Vec3() Vec3 {
+	return Vec3{a.X, a.Y, a.Z}
+}
+
+// PerspectiveDivide maps a clip-space vector to normalized device
+// coordinates. A zero w is left undivided.
+func (a Vec4) PerspectiveDivide() Vec3 {
+	if a.W == 0 {
+		return a.Vec3()
+	}
+	return Vec3{a.X / a.W, a.Y / a.W, a.Z / a.W}
+}
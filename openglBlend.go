@@ -0,0 +1,106 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/Trip1eLift/3d-engine-go/graphicsMath"
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// blendState controls how the following draws mix with what is already on
+// screen: source * sourceFactor + destination * destinationFactor.
+type blendState struct {
+	enabled           bool
+	sourceFactor      uint32
+	destinationFactor uint32
+}
+
+var (
+	BLEND_NONE     = blendState{enabled: false, sourceFactor: gl.ONE, destinationFactor: gl.ZERO}
+	BLEND_ALPHA    = blendState{enabled: true, sourceFactor: gl.SRC_ALPHA, destinationFactor: gl.ONE_MINUS_SRC_ALPHA}
+	BLEND_ADDITIVE = blendState{enabled: true, sourceFactor: gl.SRC_ALPHA, destinationFactor: gl.ONE}
+	BLEND_MULTIPLY = blendState{enabled: true, sourceFactor: gl.DST_COLOR, destinationFactor: gl.ZERO}
+)
+
+// SetBlendState applies to the following draws. It is reset to BLEND_NONE
+// at the start of every frame.
+func (OGE *openglGraphicsEngine) SetBlendState(state blendState) {
+	OGE.blend = state
+	if state.enabled {
+		gl.Enable(gl.BLEND)
+	} else {
+		gl.Disable(gl.BLEND)
+	}
+	gl.BlendFunc(state.sourceFactor, state.destinationFactor)
+}
+
+// ---------------------------- transparent queue --------------------------
+
+// transparentDraw is a queued FillMesh with the program and draw state it
+// was queued with.
+type transparentDraw struct {
+	mesh       *meshBuffer
+	program    *shaderProgram
+	model      graphicsMath.Mat4
+	view       graphicsMath.Mat4
+	projection graphicsMath.Mat4
+	blend      blendState
+	culling    bool
+	texture    *texture
+	distance   float32 // from the camera, in view space
+}
+
+// QueueTransparent fills mesh after every element has rendered its opaque
+// geometry, farthest from the camera first, so overlapping transparent
+// meshes blend in the right order. The current program, transforms, culling
+// and bound texture are used. mesh must not change or be released before the
+// end of the frame, so the buffers reused by immediate draws cannot be queued.
+func (OGE *openglGraphicsEngine) QueueTransparent(mesh *meshBuffer, blend blendState) {
+	center := graphicsMath.MultiplyMatrixVector(mesh.center, OGE.model.Mul(OGE.view))
+	OGE.transparent = append(OGE.transparent, transparentDraw{
+		mesh:       mesh,
+		program:    OGE.program,
+		model:      OGE.model,
+		view:       OGE.view,
+		projection: OGE.projection,
+		blend:      blend,
+		culling:    OGE.culling,
+		texture:    OGE.boundTexture,
+		distance:   center.Length(),
+	})
+}
+
+// drawTransparent empties the queue. Transparent meshes test against the
+// depth buffer but do not write it, so they never hide each other.
+func (OGE *openglGraphicsEngine) drawTransparent() {
+	if len(OGE.transparent) == 0 {
+		return
+	}
+	sort.SliceStable(OGE.transparent, func(i, j int) bool {
+		return OGE.transparent[i].distance > OGE.transparent[j].distance
+	})
+
+	program := OGE.program
+	OGE.SetDepthState(DEPTH_READ_ONLY)
+	for i, draw := range OGE.transparent {
+		// Before the uniforms below, which are set on the current program
+		OGE.UseShaderProgram(draw.program)
+		OGE.SetTransforms(draw.model, draw.view, draw.projection)
+		OGE.SetBlendState(draw.blend)
+		OGE.SetBackfaceCulling(draw.culling)
+		if draw.texture != nil {
+			OGE.BindTexture(draw.texture)
+		} else {
+			OGE.UnbindTexture()
+		}
+		OGE.FillMesh(draw.mesh)
+		OGE.transparent[i] = transparentDraw{}
+	}
+	OGE.transparent = OGE.transparent[:0]
+
+	OGE.UseShaderProgram(program)
+	OGE.SetDepthState(DEPTH_DEFAULT)
+	OGE.SetBlendState(BLEND_NONE)
+	OGE.SetBackfaceCulling(false)
+	OGE.UnbindTexture()
+}
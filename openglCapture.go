@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// frameCapture writes every rendered frame to dir as frame_000000.png,
+// frame_000001.png and so on.
+type frameCapture struct {
+	dir   string
+	frame int
+}
+
+// Screenshot reads what has been drawn to the back buffer so far this frame.
+// The transparent queue and the HUD are drawn after every element, so called
+// from an element it misses them; RequestScreenshot saves the finished frame.
+func (OGE *openglGraphicsEngine) Screenshot() *image.RGBA {
+	width, height := OGE.screenWidth, OGE.screenHeight
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadBuffer(gl.BACK)
+	gl.ReadPixels(0, 0, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+
+	// GL rows are bottom-up, images top-down
+	row := make([]uint8, img.Stride)
+	for y := 0; y < height/2; y++ {
+		top := img.Pix[y*img.Stride : (y+1)*img.Stride]
+		bottom := img.Pix[(height-1-y)*img.Stride : (height-y)*img.Stride]
+		copy(row, top)
+		copy(top, bottom)
+		copy(bottom, row)
+	}
+
+	// The window is opaque whatever was written to the alpha channel
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	return img
+}
+
+// SaveScreenshot writes Screenshot to a PNG file.
+func (OGE *openglGraphicsEngine) SaveScreenshot(path string) error {
+	return writePNG(path, OGE.Screenshot(), png.DefaultCompression)
+}
+
+// RequestScreenshot saves the current frame to a PNG file at path once it is
+// finished, transparent meshes and HUD included. Errors are logged.
+func (OGE *openglGraphicsEngine) RequestScreenshot(path string) {
+	OGE.screenshots = append(OGE.screenshots, path)
+}
+
+// StartFrameCapture dumps every following frame of Start into dir, which is
+// created if needed. Numbering starts again from 0.
+func (OGE *openglGraphicsEngine) StartFrameCapture(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	OGE.capture = &frameCapture{dir: dir}
+	return nil
+}
+
+func (OGE *openglGraphicsEngine) StopFrameCapture() {
+	OGE.capture = nil
+}
+
+// captureFrame runs once the frame is finished, before it is shown. A
+// failed write stops the capture instead of failing every frame after it.
+func (OGE *openglGraphicsEngine) captureFrame() {
+	if len(OGE.screenshots) > 0 {
+		img := OGE.Screenshot()
+		for _, path := range OGE.screenshots {
+			if err := writePNG(path, img, png.DefaultCompression); err != nil {
+				log.Println("screenshot:", err)
+			}
+		}
+		OGE.screenshots = OGE.screenshots[:0]
+	}
+
+	if OGE.capture == nil {
+		return
+	}
+	path := filepath.Join(OGE.capture.dir, fmt.Sprintf("frame_%06d.png", OGE.capture.frame))
+	// Favour speed, capturing already slows the loop down a lot
+	if err := writePNG(path, OGE.Screenshot(), png.BestSpeed); err != nil {
+		log.Println("frame capture stopped:", err)
+		OGE.capture = nil
+		return
+	}
+	OGE.capture.frame++
+}
+
+func writePNG(path string, img image.Image, level png.CompressionLevel) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	encoder := png.Encoder{CompressionLevel: level}
+	if err := encoder.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
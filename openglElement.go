@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Elements run in ascending priority, ties in the order they were added.
+// Adding, removing or re-prioritizing while the engine runs takes effect at
+// the start of the next frame, so the order never changes mid-loop.
+//
+// Return values of the element callbacks:
+//   - onCreate false: Start fails, or after Start the element is dropped.
+//   - onUpdate or onRender false: the element is destroyed and removed.
+//
+// onDestroy is only called for elements whose onCreate succeeded.
+
+type elementEntry struct {
+	ele      element
+	priority int
+	enabled  bool
+	created  bool
+	removed  bool
+}
+
+// active entries receive updates, draws and input.
+func (entry *elementEntry) active() bool {
+	return entry.created && entry.enabled && !entry.removed
+}
+
+func (OGE *openglGraphicsEngine) addElement(new element) {
+	OGE.addElementWithPriority(new, 0)
+}
+
+func (OGE *openglGraphicsEngine) addElementWithPriority(new element, priority int) {
+	OGE.elements = append(OGE.elements, &elementEntry{ele: new, priority: priority, enabled: true})
+	OGE.elementsDirty = true
+}
+
+// removeElement destroys the element at the start of the next frame.
+func (OGE *openglGraphicsEngine) removeElement(ele element) {
+	if entry := OGE.findElement(ele); entry != nil {
+		entry.removed = true
+		OGE.elementsDirty = true
+	}
+}
+
+// setElementEnabled pauses or resumes an element. Disabled elements keep
+// their resources and still hear about resizes, but get no updates, draws
+// or input.
+func (OGE *openglGraphicsEngine) setElementEnabled(ele element, enabled bool) {
+	if entry := OGE.findElement(ele); entry != nil {
+		entry.enabled = enabled
+	}
+}
+
+func (OGE *openglGraphicsEngine) setElementPriority(ele element, priority int) {
+	if entry := OGE.findElement(ele); entry != nil {
+		entry.priority = priority
+		OGE.elementsDirty = true
+	}
+}
+
+func (OGE *openglGraphicsEngine) findElement(ele element) *elementEntry {
+	for _, entry := range OGE.elements {
+		if entry.ele == ele && !entry.removed {
+			return entry
+		}
+	}
+	return nil
+}
+
+// createElements runs onCreate for every element added since the last call.
+// The first failure stops and is returned; the failed element is dropped.
+func (OGE *openglGraphicsEngine) createElements() error {
+	OGE.syncElements()
+	for _, entry := range OGE.elements {
+		if entry.created || entry.removed {
+			continue
+		}
+		if !entry.ele.onCreate() {
+			entry.removed = true
+			OGE.elementsDirty = true
+			return fmt.Errorf("element %T failed to create", entry.ele)
+		}
+		entry.created = true
+	}
+	return nil
+}
+
+// syncElements applies the additions, removals and priority changes made
+// since the last frame.
+func (OGE *openglGraphicsEngine) syncElements() {
+	if !OGE.elementsDirty {
+		return
+	}
+	OGE.elementsDirty = false
+
+	kept := OGE.elements[:0]
+	for _, entry := range OGE.elements {
+		if !entry.removed {
+			kept = append(kept, entry)
+		} else if entry.created {
+			entry.ele.onDestroy()
+		}
+	}
+	// Clear the tail so dropped elements can be collected
+	for i := len(kept); i < len(OGE.elements); i++ {
+		OGE.elements[i] = nil
+	}
+	OGE.elements = kept
+
+	sort.SliceStable(OGE.elements, func(i, j int) bool {
+		return OGE.elements[i].priority < OGE.elements[j].priority
+	})
+}
+
+// updateElements runs one fixed step.
+func (OGE *openglGraphicsEngine) updateElements() {
+	for _, entry := range OGE.elements {
+		if entry.active() && !entry.ele.onUpdate() {
+			entry.removed = true
+			OGE.elementsDirty = true
+		}
+	}
+}
+
+func (OGE *openglGraphicsEngine) renderElements(alpha float64) {
+	for _, entry := range OGE.elements {
+		if !entry.active() {
+			continue
+		}
+		if r, ok := entry.ele.(renderer); ok && !r.onRender(alpha) {
+			entry.removed = true
+			OGE.elementsDirty = true
+		}
+	}
+}
+
+// destroyElements tears down every created element, last to first.
+func (OGE *openglGraphicsEngine) destroyElements() {
+	for i := len(OGE.elements) - 1; i >= 0; i-- {
+		if entry := OGE.elements[i]; entry.created {
+			entry.ele.onDestroy()
+		}
+	}
+	OGE.elements = nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import "fmt"
+
+// Construction errors are typed so callers can tell a machine without the
+// requested GL version from a broken shader, e.g. to fall back to the
+// console renderer. Use errors.As to check for them.
+
+// windowError means GLFW could not start or open a window at all.
+type windowError struct {
+	err error
+}
+
+func (e *windowError) Error() string {
+	return fmt.Sprintf("failed to create window: %v", e.err)
+}
+
+func (e *windowError) Unwrap() error {
+	return e.err
+}
+
+// contextError means the requested OpenGL version or profile is not
+// available, or its functions could not be loaded.
+type contextError struct {
+	major, minor int
+	err          error
+}
+
+func (e *contextError) Error() string {
+	return fmt.Sprintf("failed to create OpenGL %d.%d context: %v", e.major, e.minor, e.err)
+}
+
+func (e *contextError) Unwrap() error {
+	return e.err
+}
+
+// shaderError is a compile or link failure with the driver's log.
+type shaderError struct {
+	stage string // "compile" or "link"
+	name  string
+	log   string
+}
+
+func (e *shaderError) Error() string {
+	return fmt.Sprintf("failed to %v %v: %v", e.stage, e.name, e.log)
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"runtime"
 	"strings"
 	"time"
 
+	"github.com/Trip1eLift/3d-engine-go/graphicsMath"
 	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/glfw/v3.2/glfw"
 )
@@ -19,27 +22,65 @@ type RGB struct {
 	red, green, blue float32
 }
 
+// RGBA is RGB with opacity, 1 opaque and 0 invisible. Alpha only shows with
+// a blend state such as BLEND_ALPHA.
+type RGBA struct {
+	red, green, blue, alpha float32
+}
+
+func (color RGB) WithAlpha(alpha float32) RGBA {
+	return RGBA{color.red, color.green, color.blue, alpha}
+}
+
+// The built-in shaders get a #version line matching the context, see
+// glslVersion.
 const (
-	// input vec3 as position
+	// input vec3 as position, transformed by model, view and projection
 	vertexShaderSource = `
-		#version 410
-		in vec2 vertice_point;
+		in vec3 vertice_point;
 		in vec4 vertice_color;
+		in vec2 vertice_uv;
+		uniform mat4 model;
+		uniform mat4 view;
+		uniform mat4 projection;
 		out vec4 color;
+		out vec2 uv;
 		void main() {
 			color = vertice_color;
-			gl_Position = vec4(vertice_point, 0.0, 1.0);
+			uv = vertice_uv;
+			gl_Position = projection * view * model * vec4(vertice_point, 1.0);
 		}
-	` + "\x00"
-	// output colour
+	`
+	// output colour, tinting the bound texture if there is one
 	fragmentShaderSource = `
-		#version 410
 		in vec4 color;
+		in vec2 uv;
+		uniform sampler2D tex;
+		uniform int useTexture;
 		out vec4 frag_colour;
 		void main() {
-			frag_colour = vec4(color);
+			if (useTexture != 0) {
+				frag_colour = texture(tex, uv) * color;
+			} else {
+				frag_colour = vec4(color);
+			}
 		}
-	` + "\x00"
+	`
+)
+
+// depthState controls how the following draws use the depth buffer.
+// function is a GL comparison such as gl.LESS or gl.LEQUAL.
+type depthState struct {
+	test     bool
+	write    bool
+	function uint32
+}
+
+var (
+	DEPTH_DEFAULT = depthState{test: true, write: true, function: gl.LESS}
+	DEPTH_NONE    = depthState{test: false, write: false, function: gl.ALWAYS}
+	// Hidden behind opaque geometry, but not hiding what is drawn after
+	DEPTH_READ_ONLY = depthState{test: true, write: false, function: gl.LESS}
 )
 
 var (
@@ -49,52 +90,158 @@ var (
 	BLUE  = RGB{0.0, 0.0, 1.0}
 )
 
+// onUpdate runs at a fixed rate, delta is always fixedStep seconds.
+// See openglElement.go for what the return values mean.
 type element interface {
 	onCreate() bool
 	onUpdate() bool
+	onDestroy()
+}
+
+// Elements implementing renderer draw once per displayed frame. alpha in
+// [0, 1) is how far the frame lies between the last update and the next, for
+// interpolating state.
+type renderer interface {
+	onRender(alpha float64) bool
 }
 
+const (
+	DEFAULT_FIXED_STEP = 1.0 / 60.0
+	MAX_FRAME_TIME     = 0.25 // seconds of simulation caught up per frame at most
+)
+
 type openglGraphicsEngine struct {
-	screenWidth  int
-	screenHeight int
-	title        string
-	window       *glfw.Window
-	program      uint32
-	elements     []element
-	delta        float64
-	fps          float64
+	screenWidth      int
+	screenHeight     int
+	title            string
+	window           *glfw.Window
+	windowed         windowedPlacement // restored when leaving fullscreen
+	program          *shaderProgram    // in use by the current draws
+	shaders          []*shaderProgram
+	glsl             string // #version line of the context, see glslVersion
+	elements         []*elementEntry
+	elementsDirty    bool // elements changed since the last syncElements
+	camera           *graphicsMath.Camera
+	input            inputState
+	meshes           map[*meshBuffer]struct{}
+	textures         map[*texture]struct{}
+	scratch          *meshBuffer // reused by the immediate draws, DrawTriangle, DrawLines...
+	delta            float64     // seconds per update
+	frameDelta       float64     // seconds since the previous rendered frame
+	fixedStep        float64
+	fps              float64 // render rate limit when not using vsync, 0 for none
+	vsync            bool
+	capture          *frameCapture // nil unless capturing frames
+	screenshots      []string      // paths saved once this frame is finished
+	font             *texture
+	textBuffer       *meshBuffer // reused by DrawText
+	hudVisible       bool
+	stats            drawStats // of the frame being drawn
+	transparent      []transparentDraw
+	frameTimeAverage float64
+	// Draw state set through the setters, so it can be restored
+	model        graphicsMath.Mat4
+	view         graphicsMath.Mat4
+	projection   graphicsMath.Mat4
+	depth        depthState
+	blend        blendState
+	culling      bool
+	boundTexture *texture
+}
+
+// engineOptions configures constructOpenglGraphicsEngine. Start from
+// DEFAULT_OPTIONS and change what you need.
+type engineOptions struct {
+	width      int
+	height     int
+	title      string
+	targetFPS  float64 // render rate limit when vsync is off, 0 for none
+	glMajor    int     // 3.2 at least, the engine needs a core profile
+	glMinor    int
+	vsync      bool
+	samples    int  // MSAA samples per pixel, 0 to disable
+	fullscreen bool // on the primary monitor at its current video mode
+	resizable  bool
+	hud        bool // show the HUD from the start, see ToggleHUD
+}
+
+var DEFAULT_OPTIONS = engineOptions{
+	width:     500,
+	height:    500,
+	title:     "3D engine",
+	targetFPS: 60,
+	glMajor:   4,
+	glMinor:   1,
+	resizable: true,
 }
 
-func constructOpenglGraphicsEngine(width int, height int, title string, targetFPS float64) *openglGraphicsEngine {
+// constructOpenglGraphicsEngine opens the window and GL context. Failures are
+// a *windowError, *contextError or *shaderError, and leave GLFW terminated.
+func constructOpenglGraphicsEngine(options engineOptions) (*openglGraphicsEngine, error) {
 	OGE := &openglGraphicsEngine{}
-	OGE.screenWidth = width
-	OGE.screenHeight = height
-	OGE.title = title
-	OGE.fps = targetFPS
+	OGE.screenWidth = options.width
+	OGE.screenHeight = options.height
+	OGE.title = options.title
+	OGE.fps = options.targetFPS
+	OGE.vsync = options.vsync
+	OGE.hudVisible = options.hud
+	OGE.fixedStep = DEFAULT_FIXED_STEP
+	OGE.delta = OGE.fixedStep
+	OGE.camera = graphicsMath.NewCamera(float32(options.height) / float32(options.width))
 
 	runtime.LockOSThread()
 
-	OGE.window = initGlfw(OGE.screenWidth, OGE.screenHeight, OGE.title)
-	OGE.program = initOpenGL()
-	return OGE
+	window, err := initGlfw(options)
+	if err != nil {
+		return nil, err
+	}
+	OGE.window = window
+
+	prog, err := initOpenGL(options)
+	if err != nil {
+		glfw.Terminate()
+		return nil, err
+	}
+	OGE.shaders = append(OGE.shaders, prog)
+	OGE.program = OGE.shaders[0]
+	OGE.glsl = glslVersion(options.glMajor, options.glMinor)
+
+	// Leaving fullscreen restores the requested size, centred
+	OGE.windowed = windowedPlacement{width: options.width, height: options.height}
+	if options.fullscreen {
+		mode := glfw.GetPrimaryMonitor().GetVideoMode()
+		OGE.windowed.x = (mode.Width - options.width) / 2
+		OGE.windowed.y = (mode.Height - options.height) / 2
+	}
+
+	OGE.initInput()
+	OGE.initWindow()
+	OGE.meshes = make(map[*meshBuffer]struct{})
+	OGE.textures = make(map[*texture]struct{})
+	OGE.scratch = OGE.UploadMesh(nil, nil)
+	OGE.initText()
+	return OGE, nil
 }
 
 func (OGE *openglGraphicsEngine) destructor() {
 	defer glfw.Terminate()
+	OGE.destroyElements()
+	OGE.releaseAllMeshes()
+	OGE.releaseAllTextures()
+	for _, prog := range OGE.shaders {
+		prog.delete()
+	}
 }
 
+// DrawTriangle draws the triangle's outline.
 func (OGE *openglGraphicsEngine) DrawTriangle(vertices []float32, colors []float32) {
-	vao := makeVao(vertices, colors)
-	gl.BindVertexArray(vao)
-	gl.DrawArrays(gl.TRIANGLES, 0, 3)
-	gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
+	OGE.UpdateMesh(OGE.scratch, vertices, colors)
+	OGE.drawMeshTriangles(OGE.scratch, gl.LINE)
 }
 
 func (OGE *openglGraphicsEngine) FillTriangle(vertices []float32, colors []float32) {
-	vao := makeVao(vertices, colors)
-	gl.BindVertexArray(vao)
-	gl.DrawArrays(gl.TRIANGLES, 0, 3)
-	gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	OGE.UpdateMesh(OGE.scratch, vertices, colors)
+	OGE.drawMeshTriangles(OGE.scratch, gl.FILL)
 }
 
 func (OGE *openglGraphicsEngine) ScaleRGB(color RGB, factor float32) RGB {
@@ -114,51 +261,171 @@ func (OGE *openglGraphicsEngine) ScaleRGB(color RGB, factor float32) RGB {
 }
 
 func (OGE *openglGraphicsEngine) TriangleColorEvenly(color RGB) []float32 {
+	return OGE.TriangleColorEvenlyRGBA(color.WithAlpha(1))
+}
+
+func (OGE *openglGraphicsEngine) TriangleColorEvenlyRGBA(color RGBA) []float32 {
 	return []float32{
-		color.red, color.green, color.blue, 1,
-		color.red, color.green, color.blue, 1,
-		color.red, color.green, color.blue, 1,
+		color.red, color.green, color.blue, color.alpha,
+		color.red, color.green, color.blue, color.alpha,
+		color.red, color.green, color.blue, color.alpha,
 	}
 }
 
+// SixP2Triangle is a flat triangle at z = 0, e.g. in screen space with the
+// default identity transforms.
 func (OGE *openglGraphicsEngine) SixP2Triangle(x1, y1, x2, y2, x3, y3 float32) []float32 {
 	return []float32{
-		x1, y1,
-		x2, y2,
-		x3, y3,
+		x1, y1, 0,
+		x2, y2, 0,
+		x3, y3, 0,
+	}
+}
+
+func (OGE *openglGraphicsEngine) MeshVertices(mesh graphicsMath.Mesh) []float32 {
+	vertices := make([]float32, 0, len(mesh.Tris)*3*positionSize)
+	for _, tri := range mesh.Tris {
+		for _, p := range tri.P {
+			vertices = append(vertices, p.X, p.Y, p.Z)
+		}
+	}
+	return vertices
+}
+
+func (OGE *openglGraphicsEngine) MeshUVs(mesh graphicsMath.Mesh) []float32 {
+	uvs := make([]float32, 0, len(mesh.Tris)*3*uvSize)
+	for _, tri := range mesh.Tris {
+		for _, t := range tri.T {
+			uvs = append(uvs, t.X, t.Y)
+		}
+	}
+	return uvs
+}
+
+func (OGE *openglGraphicsEngine) MeshColorEvenly(mesh graphicsMath.Mesh, color RGB) []float32 {
+	return OGE.MeshColorEvenlyRGBA(mesh, color.WithAlpha(1))
+}
+
+func (OGE *openglGraphicsEngine) MeshColorEvenlyRGBA(mesh graphicsMath.Mesh, color RGBA) []float32 {
+	colors := make([]float32, 0, len(mesh.Tris)*3*colorSize)
+	for range mesh.Tris {
+		colors = append(colors, OGE.TriangleColorEvenlyRGBA(color)...)
+	}
+	return colors
+}
+
+// SetTransforms sets the model, view and projection matrices used by the
+// following draws. They are reset to identity at the start of every frame.
+func (OGE *openglGraphicsEngine) SetTransforms(model, view, projection graphicsMath.Mat4) {
+	OGE.model, OGE.view, OGE.projection = model, view, projection
+	OGE.program.SetMat4("model", model)
+	OGE.program.SetMat4("view", view)
+	OGE.program.SetMat4("projection", projection)
+}
+
+// SetModelMatrix changes only the model matrix, for drawing several objects
+// with the same camera.
+func (OGE *openglGraphicsEngine) SetModelMatrix(model graphicsMath.Mat4) {
+	OGE.model = model
+	OGE.program.SetMat4("model", model)
+}
+
+func (OGE *openglGraphicsEngine) ResetTransforms() {
+	identity := graphicsMath.MakeIdentity()
+	OGE.SetTransforms(identity, identity, identity)
+}
+
+// SetDepthState applies to the following draws. It is reset to
+// DEPTH_DEFAULT at the start of every frame.
+func (OGE *openglGraphicsEngine) SetDepthState(state depthState) {
+	OGE.depth = state
+	if state.test {
+		gl.Enable(gl.DEPTH_TEST)
+	} else {
+		gl.Disable(gl.DEPTH_TEST)
 	}
+	gl.DepthMask(state.write)
+	gl.DepthFunc(state.function)
 }
 
-func (OGE *openglGraphicsEngine) Start() {
-	for _, ele := range OGE.elements {
-		ele.onCreate()
+// SetBackfaceCulling skips triangles wound counter-clockwise on screen.
+func (OGE *openglGraphicsEngine) SetBackfaceCulling(enabled bool) {
+	OGE.culling = enabled
+	if enabled {
+		gl.Enable(gl.CULL_FACE)
+	} else {
+		gl.Disable(gl.CULL_FACE)
+	}
+}
+
+// Start runs until the window is closed. It fails if an element's onCreate
+// returns false; call destructor either way.
+func (OGE *openglGraphicsEngine) Start() error {
+	if err := OGE.createElements(); err != nil {
+		return err
+	}
+
+	if OGE.vsync {
+		glfw.SwapInterval(1)
+	} else {
+		glfw.SwapInterval(0)
 	}
 
+	previousFrameTime := time.Now()
+	accumulator := 0.0
+
 	for !OGE.window.ShouldClose() {
 		frameStartTime := time.Now()
+		OGE.frameDelta = frameStartTime.Sub(previousFrameTime).Seconds()
+		previousFrameTime = frameStartTime
+		OGE.frameTimeAverage += (OGE.frameDelta - OGE.frameTimeAverage) * 0.1
 
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-		gl.UseProgram(OGE.program)
+		// Elements added at runtime are created here, a failure only drops them
+		if err := OGE.createElements(); err != nil {
+			log.Println(err)
+		}
 
-		for _, ele := range OGE.elements {
-			ele.onUpdate()
+		// After a stall, drop time instead of running a burst of updates
+		accumulator += math.Min(OGE.frameDelta, MAX_FRAME_TIME)
+
+		OGE.dispatchInput()
+		for accumulator >= OGE.fixedStep {
+			OGE.updateElements()
+			OGE.endInputFrame()
+			accumulator -= OGE.fixedStep
 		}
+		alpha := accumulator / OGE.fixedStep
+
+		// glClear skips the depth buffer while depth writes are off, so the
+		// depth state last frame ended with must not outlive it
+		OGE.SetDepthState(DEPTH_DEFAULT)
+		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+		OGE.stats = drawStats{}
+		OGE.reloadShaders()
+		OGE.UseShaderProgram(OGE.shaders[0])
+		OGE.ResetTransforms()
+		OGE.UnbindTexture()
+		OGE.SetBackfaceCulling(false)
+		OGE.SetBlendState(BLEND_NONE)
+
+		OGE.renderElements(alpha)
+		OGE.drawTransparent()
+		OGE.drawHUD()
+		OGE.captureFrame()
 
 		glfw.PollEvents()
 		OGE.window.SwapBuffers()
 
-		// Control framerate
-		frameTime := 1.0 / float64(OGE.fps)
-		for time.Since(frameStartTime).Seconds() < frameTime {
-			time.Sleep(time.Duration(frameTime/4) * time.Second)
+		// Control framerate, vsync already blocks in SwapBuffers
+		if !OGE.vsync && OGE.fps > 0 {
+			frameTime := time.Duration(float64(time.Second) / OGE.fps)
+			time.Sleep(frameTime - time.Since(frameStartTime))
+		}
+		if OGE.frameDelta > 0 {
+			OGE.window.SetTitle(OGE.title + fmt.Sprintf(" FPS: %.1f", 1.0/OGE.frameDelta))
 		}
-		OGE.window.SetTitle(OGE.title + fmt.Sprintf(" FPS: %.1f", 1.0/time.Since(frameStartTime).Seconds()))
-		OGE.delta = time.Since(frameStartTime).Seconds() * OGE.fps
 	}
-}
-
-func (OGE *openglGraphicsEngine) addElement(new element) {
-	OGE.elements = append(OGE.elements, new)
+	return nil
 }
 
 // --------------------- test code -----------------------------
@@ -169,22 +436,28 @@ func (OGE *openglGraphicsEngine) addElement(new element) {
 // 		width        = 500
 // 		height       = 500
 // 		triangleTest = []float32{
-// 			0, 0.5, // top
-// 			-0.5, -0.5, // left
-// 			0.5, -0.5, // right
+// 			0, 0.5, 0, // top
+// 			-0.5, -0.5, 0, // left
+// 			0.5, -0.5, 0, // right
 // 		}
 // 		triangleTest2 = []float32{
-// 			0.0, -0.5, // bottom
-// 			0.5, 0.5, // right
-// 			-0.5, 0.5, // letf
+// 			0.0, -0.5, 0, // bottom
+// 			0.5, 0.5, 0, // right
+// 			-0.5, 0.5, 0, // letf
 // 		}
 // 	)
-// 	oge := constructOpenglGraphicsEngine(width, height, "OGE TEST", 60)
+// 	options := DEFAULT_OPTIONS
+// 	options.width, options.height, options.title = width, height, "OGE TEST"
+// 	oge, err := constructOpenglGraphicsEngine(options)
+// 	if err != nil {
+// 		log.Fatalln(err)
+// 	}
 
 // 	for !oge.window.ShouldClose() {
 
 // 		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-// 		gl.UseProgram(oge.program)
+// 		oge.UseShaderProgram(oge.program)
+// 		oge.ResetTransforms()
 
 // 		oge.FillTriangle(triangleTest2, oge.TriangleColorEvenly(WHITE))
 // 		oge.DrawTriangle(triangleTest, oge.TriangleColorEvenly(oge.ScaleRGB(BLUE, 0.5)))
@@ -198,60 +471,96 @@ func (OGE *openglGraphicsEngine) addElement(new element) {
 
 // ------------------------  Helper funcsions -----------------------------------
 
-func initGlfw(screenWidth int, screenHeight int, name string) *glfw.Window {
+func initGlfw(options engineOptions) (*glfw.Window, error) {
+	if options.glMajor < 3 || (options.glMajor == 3 && options.glMinor < 2) {
+		return nil, &contextError{options.glMajor, options.glMinor, errors.New("the core profile needs OpenGL 3.2 or newer")}
+	}
 	if err := glfw.Init(); err != nil {
-		panic(err)
+		return nil, &windowError{err}
 	}
 
-	glfw.WindowHint(glfw.Resizable, glfw.False)
-	glfw.WindowHint(glfw.ContextVersionMajor, 4)
-	glfw.WindowHint(glfw.ContextVersionMinor, 1)
+	resizable := glfw.False
+	if options.resizable {
+		resizable = glfw.True
+	}
+	glfw.WindowHint(glfw.Resizable, resizable)
+	glfw.WindowHint(glfw.DepthBits, 24)
+	glfw.WindowHint(glfw.Samples, options.samples)
+	glfw.WindowHint(glfw.ContextVersionMajor, options.glMajor)
+	glfw.WindowHint(glfw.ContextVersionMinor, options.glMinor)
 	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
 	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
 
-	window, err := glfw.CreateWindow(screenWidth, screenHeight, name, nil, nil)
+	width, height := options.width, options.height
+	var monitor *glfw.Monitor
+	if options.fullscreen {
+		monitor = glfw.GetPrimaryMonitor()
+		if monitor == nil {
+			glfw.Terminate()
+			return nil, &windowError{errors.New("no monitor for fullscreen")}
+		}
+		mode := monitor.GetVideoMode()
+		width, height = mode.Width, mode.Height
+		glfw.WindowHint(glfw.RefreshRate, mode.RefreshRate)
+	}
+
+	window, err := glfw.CreateWindow(width, height, options.title, monitor, nil)
 	if err != nil {
-		panic(err)
+		glfw.Terminate()
+		// The window system works but the driver lacks the requested version
+		if glfwErr, ok := err.(*glfw.Error); ok && (glfwErr.Code == glfw.VersionUnavailable || glfwErr.Code == glfw.APIUnavailable) {
+			return nil, &contextError{options.glMajor, options.glMinor, err}
+		}
+		return nil, &windowError{err}
+	}
+	// GLFW 3.2 can fail without reporting an error
+	if window == nil {
+		glfw.Terminate()
+		return nil, &windowError{errors.New("no window returned")}
 	}
 	window.MakeContextCurrent()
 
-	return window
+	return window, nil
 }
 
-func initOpenGL() uint32 {
+func initOpenGL(options engineOptions) (*shaderProgram, error) {
 	if err := gl.Init(); err != nil {
-		panic(err)
+		return nil, &contextError{options.glMajor, options.glMinor, err}
 	}
 	version := gl.GoStr(gl.GetString(gl.VERSION))
 	log.Println("OpenGL version", version)
 
-	vertexShader, err := compileShader(vertexShaderSource, gl.VERTEX_SHADER)
+	glsl := glslVersion(options.glMajor, options.glMinor)
+	prog, err := newShaderProgram(glsl+vertexShaderSource, glsl+fragmentShaderSource)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	fragmentShader, err := compileShader(fragmentShaderSource, gl.FRAGMENT_SHADER)
-	if err != nil {
-		panic(err)
+
+	if options.samples > 0 {
+		gl.Enable(gl.MULTISAMPLE)
 	}
 
-	prog := gl.CreateProgram()
-	gl.AttachShader(prog, vertexShader)
-	gl.AttachShader(prog, fragmentShader)
-	point_ptr := []uint8("vertice_point")
-	color_ptr := []uint8("vertice_color")
-	gl.BindAttribLocation(prog, 0, &point_ptr[0])
-	gl.BindAttribLocation(prog, 1, &color_ptr[0])
-	gl.LinkProgram(prog)
-	gl.ValidateProgram(prog)
-	gl.DeleteShader(vertexShader)
-	gl.DeleteShader(fragmentShader)
-	return prog
+	gl.Enable(gl.DEPTH_TEST)
+	gl.DepthFunc(gl.LESS)
+
+	// Meshes are wound clockwise when seen from the front
+	gl.FrontFace(gl.CW)
+	gl.CullFace(gl.BACK)
+	return prog, nil
 }
 
-func compileShader(source string, shaderType uint32) (uint32, error) {
+// glslVersion is the #version line for a GL version, 3.2 being GLSL 1.50.
+func glslVersion(major int, minor int) string {
+	if major == 3 && minor < 3 {
+		return "#version 150\n"
+	}
+	return fmt.Sprintf("#version %d%d0\n", major, minor)
+}
+
+func compileShader(source string, shaderType uint32, name string) (uint32, error) {
 	shader := gl.CreateShader(shaderType)
 
-	csources, free := gl.Strs(source)
+	csources, free := gl.Strs(source + "\x00")
 	gl.ShaderSource(shader, 1, csources, nil)
 	free()
 	gl.CompileShader(shader)
@@ -264,30 +573,9 @@ func compileShader(source string, shaderType uint32) (uint32, error) {
 
 		log := strings.Repeat("\x00", int(logLength+1))
 		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
+		gl.DeleteShader(shader)
 
-		return 0, fmt.Errorf("failed to compile %v: %v", source, log)
+		return 0, &shaderError{stage: "compile", name: name, log: strings.TrimRight(log, "\x00")}
 	}
 	return shader, nil
 }
-
-func makeVao(vertices []float32, colors []float32) uint32 {
-	var vao uint32    // Vertex Array Object
-	var vbo [2]uint32 // Vertex Buffer Object
-	gl.GenVertexArrays(1, &vao)
-	gl.BindVertexArray(vao)
-	gl.GenBuffers(2, &vbo[0])
-
-	// Vertices
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo[0])
-	gl.BufferData(gl.ARRAY_BUFFER, 4*len(vertices), gl.Ptr(vertices), gl.STATIC_DRAW)
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 0, nil)
-
-	// Color
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo[1])
-	gl.BufferData(gl.ARRAY_BUFFER, 4*len(colors), gl.Ptr(colors), gl.STATIC_DRAW)
-	gl.EnableVertexAttribArray(1)
-	gl.VertexAttribPointer(1, 4, gl.FLOAT, false, 0, nil)
-
-	return vao
-}
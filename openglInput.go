@@ -0,0 +1,133 @@
+package main
+
+import "github.com/go-gl/glfw/v3.2/glfw"
+
+type inputEventType int
+
+const (
+	KEY_EVENT inputEventType = iota
+	MOUSE_BUTTON_EVENT
+	CURSOR_EVENT
+	SCROLL_EVENT
+)
+
+type inputEvent struct {
+	kind   inputEventType
+	key    glfw.Key         // KEY_EVENT
+	button glfw.MouseButton // MOUSE_BUTTON_EVENT
+	action glfw.Action      // KEY_EVENT, MOUSE_BUTTON_EVENT
+	mods   glfw.ModifierKey // KEY_EVENT, MOUSE_BUTTON_EVENT
+	x, y   float64          // cursor position for CURSOR_EVENT, offset for SCROLL_EVENT
+}
+
+// Elements implementing inputListener receive every input event before
+// their onUpdate. Returning true consumes the event so elements later in the
+// update order do not see it.
+type inputListener interface {
+	onInput(event inputEvent) bool
+}
+
+type inputState struct {
+	keys         map[glfw.Key]bool
+	mouseButtons map[glfw.MouseButton]bool
+	cursorX      float64
+	cursorY      float64
+	cursorSeen   bool
+	mouseDeltaX  float64
+	mouseDeltaY  float64
+	scrollX      float64
+	scrollY      float64
+	events       []inputEvent
+}
+
+func (OGE *openglGraphicsEngine) initInput() {
+	OGE.input.keys = make(map[glfw.Key]bool)
+	OGE.input.mouseButtons = make(map[glfw.MouseButton]bool)
+
+	OGE.window.SetKeyCallback(func(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+		// Repeats keep the key down
+		if action == glfw.Press {
+			OGE.input.keys[key] = true
+		} else if action == glfw.Release {
+			OGE.input.keys[key] = false
+		}
+		OGE.input.events = append(OGE.input.events, inputEvent{kind: KEY_EVENT, key: key, action: action, mods: mods})
+	})
+
+	OGE.window.SetMouseButtonCallback(func(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+		OGE.input.mouseButtons[button] = action == glfw.Press
+		OGE.input.events = append(OGE.input.events, inputEvent{kind: MOUSE_BUTTON_EVENT, button: button, action: action, mods: mods})
+	})
+
+	OGE.window.SetCursorPosCallback(func(w *glfw.Window, xpos float64, ypos float64) {
+		// The first position only sets the origin, otherwise the first delta jumps
+		if OGE.input.cursorSeen {
+			OGE.input.mouseDeltaX += xpos - OGE.input.cursorX
+			OGE.input.mouseDeltaY += ypos - OGE.input.cursorY
+		}
+		OGE.input.cursorX, OGE.input.cursorY = xpos, ypos
+		OGE.input.cursorSeen = true
+		OGE.input.events = append(OGE.input.events, inputEvent{kind: CURSOR_EVENT, x: xpos, y: ypos})
+	})
+
+	OGE.window.SetScrollCallback(func(w *glfw.Window, xoff float64, yoff float64) {
+		OGE.input.scrollX += xoff
+		OGE.input.scrollY += yoff
+		OGE.input.events = append(OGE.input.events, inputEvent{kind: SCROLL_EVENT, x: xoff, y: yoff})
+	})
+}
+
+// dispatchInput delivers the events polled since the last frame.
+func (OGE *openglGraphicsEngine) dispatchInput() {
+	for _, event := range OGE.input.events {
+		for _, entry := range OGE.elements {
+			if !entry.active() {
+				continue
+			}
+			if listener, ok := entry.ele.(inputListener); ok && listener.onInput(event) {
+				break
+			}
+		}
+	}
+	OGE.input.events = OGE.input.events[:0]
+}
+
+// endInputFrame clears the accumulators once every element has read them in
+// an update. Frames without an update keep accumulating.
+func (OGE *openglGraphicsEngine) endInputFrame() {
+	OGE.input.mouseDeltaX, OGE.input.mouseDeltaY = 0, 0
+	OGE.input.scrollX, OGE.input.scrollY = 0, 0
+}
+
+func (OGE *openglGraphicsEngine) IsKeyDown(key glfw.Key) bool {
+	return OGE.input.keys[key]
+}
+
+func (OGE *openglGraphicsEngine) IsMouseButtonDown(button glfw.MouseButton) bool {
+	return OGE.input.mouseButtons[button]
+}
+
+// CursorPosition is in screen coordinates from the top-left of the window.
+func (OGE *openglGraphicsEngine) CursorPosition() (float64, float64) {
+	return OGE.input.cursorX, OGE.input.cursorY
+}
+
+// MouseDelta is how far the cursor moved since the previous update.
+func (OGE *openglGraphicsEngine) MouseDelta() (float64, float64) {
+	return OGE.input.mouseDeltaX, OGE.input.mouseDeltaY
+}
+
+// ScrollDelta is the scroll offset accumulated since the previous update.
+func (OGE *openglGraphicsEngine) ScrollDelta() (float64, float64) {
+	return OGE.input.scrollX, OGE.input.scrollY
+}
+
+// SetCursorCaptured hides the cursor and keeps it in the window, so mouse
+// deltas are unbounded, e.g. for mouse-look.
+func (OGE *openglGraphicsEngine) SetCursorCaptured(captured bool) {
+	if captured {
+		OGE.window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+	} else {
+		OGE.window.SetInputMode(glfw.CursorMode, glfw.CursorNormal)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"unsafe"
+
+	"github.com/Trip1eLift/3d-engine-go/graphicsMath"
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+const (
+	positionSize = 3 // floats per vertex position
+	colorSize    = 4 // floats per vertex color
+	uvSize       = 2 // floats per texture coordinate
+)
+
+// meshBuffer is vertex data living on the GPU. Upload it once, draw it
+// every frame with one call, and release it when done.
+type meshBuffer struct {
+	vao         uint32    // Vertex Array Object
+	vbo         [3]uint32 // Vertex Buffer Objects: positions, colors, uvs
+	vertexCount int32
+	center      graphicsMath.Vec3 // of the bounding box, for sorting
+}
+
+func (OGE *openglGraphicsEngine) UploadMesh(vertices []float32, colors []float32) *meshBuffer {
+	mesh := &meshBuffer{}
+	gl.GenVertexArrays(1, &mesh.vao)
+	gl.BindVertexArray(mesh.vao)
+	gl.GenBuffers(3, &mesh.vbo[0])
+
+	// Vertices
+	gl.BindBuffer(gl.ARRAY_BUFFER, mesh.vbo[0])
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(0, positionSize, gl.FLOAT, false, 0, nil)
+
+	// Color
+	gl.BindBuffer(gl.ARRAY_BUFFER, mesh.vbo[1])
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointer(1, colorSize, gl.FLOAT, false, 0, nil)
+
+	// UV, enabled once a mesh has texture coordinates
+	gl.BindBuffer(gl.ARRAY_BUFFER, mesh.vbo[2])
+	gl.VertexAttribPointer(2, uvSize, gl.FLOAT, false, 0, nil)
+
+	OGE.UpdateMesh(mesh, vertices, colors)
+	OGE.meshes[mesh] = struct{}{}
+	return mesh
+}
+
+// UploadTexturedMesh is UploadMesh with a texture coordinate per vertex.
+func (OGE *openglGraphicsEngine) UploadTexturedMesh(vertices []float32, colors []float32, uvs []float32) *meshBuffer {
+	mesh := OGE.UploadMesh(vertices, colors)
+	OGE.UpdateMeshUVs(mesh, uvs)
+	return mesh
+}
+
+// UpdateMesh replaces the mesh's vertex data. The vertex count may change.
+func (OGE *openglGraphicsEngine) UpdateMesh(mesh *meshBuffer, vertices []float32, colors []float32) {
+	gl.BindBuffer(gl.ARRAY_BUFFER, mesh.vbo[0])
+	gl.BufferData(gl.ARRAY_BUFFER, 4*len(vertices), glPtr(vertices), gl.DYNAMIC_DRAW)
+	gl.BindBuffer(gl.ARRAY_BUFFER, mesh.vbo[1])
+	gl.BufferData(gl.ARRAY_BUFFER, 4*len(colors), glPtr(colors), gl.DYNAMIC_DRAW)
+	mesh.vertexCount = int32(len(vertices) / positionSize)
+	mesh.center = boundsCenter(vertices)
+}
+
+// UpdateMeshUVs replaces the mesh's texture coordinates, one per vertex.
+func (OGE *openglGraphicsEngine) UpdateMeshUVs(mesh *meshBuffer, uvs []float32) {
+	gl.BindVertexArray(mesh.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, mesh.vbo[2])
+	gl.BufferData(gl.ARRAY_BUFFER, 4*len(uvs), glPtr(uvs), gl.DYNAMIC_DRAW)
+	gl.EnableVertexAttribArray(2)
+}
+
+// DrawMesh draws the mesh's triangles as wireframe.
+func (OGE *openglGraphicsEngine) DrawMesh(mesh *meshBuffer) {
+	OGE.drawMeshTriangles(mesh, gl.LINE)
+}
+
+// FillMesh draws the mesh's triangles filled.
+func (OGE *openglGraphicsEngine) FillMesh(mesh *meshBuffer) {
+	OGE.drawMeshTriangles(mesh, gl.FILL)
+}
+
+// DrawMeshLines draws the mesh's vertices in pairs as line segments, e.g. a
+// bounding box uploaded once.
+func (OGE *openglGraphicsEngine) DrawMeshLines(mesh *meshBuffer) {
+	OGE.drawMeshPrimitives(mesh, gl.LINES)
+}
+
+// drawMeshTriangles sets the polygon mode for this draw only, so wireframe
+// never leaks into the next draw.
+func (OGE *openglGraphicsEngine) drawMeshTriangles(mesh *meshBuffer, polygonMode uint32) {
+	gl.PolygonMode(gl.FRONT_AND_BACK, polygonMode)
+	OGE.drawMeshPrimitives(mesh, gl.TRIANGLES)
+	gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+}
+
+func (OGE *openglGraphicsEngine) drawMeshPrimitives(mesh *meshBuffer, primitive uint32) {
+	if mesh.vertexCount == 0 {
+		return
+	}
+	gl.BindVertexArray(mesh.vao)
+	gl.DrawArrays(primitive, 0, mesh.vertexCount)
+	OGE.stats.drawCalls++
+	if primitive == gl.TRIANGLES {
+		OGE.stats.triangles += int(mesh.vertexCount / 3)
+	}
+}
+
+// ReleaseMesh frees the mesh's GPU buffers. The mesh must not be used again.
+func (OGE *openglGraphicsEngine) ReleaseMesh(mesh *meshBuffer) {
+	if _, ok := OGE.meshes[mesh]; !ok {
+		return
+	}
+	gl.DeleteBuffers(3, &mesh.vbo[0])
+	gl.DeleteVertexArrays(1, &mesh.vao)
+	delete(OGE.meshes, mesh)
+}
+
+func (OGE *openglGraphicsEngine) releaseAllMeshes() {
+	for mesh := range OGE.meshes {
+		OGE.ReleaseMesh(mesh)
+	}
+}
+
+func boundsCenter(vertices []float32) graphicsMath.Vec3 {
+	if len(vertices) < positionSize {
+		return graphicsMath.Vec3{}
+	}
+	var min, max [positionSize]float32
+	copy(min[:], vertices)
+	copy(max[:], vertices)
+	for i, v := range vertices {
+		axis := i % positionSize
+		if v < min[axis] {
+			min[axis] = v
+		}
+		if v > max[axis] {
+			max[axis] = v
+		}
+	}
+	return graphicsMath.Vec3{X: (min[0] + max[0]) / 2, Y: (min[1] + max[1]) / 2, Z: (min[2] + max[2]) / 2}
+}
+
+// glPtr is gl.Ptr that also accepts an empty slice.
+func glPtr(data []float32) unsafe.Pointer {
+	if len(data) == 0 {
+		return nil
+	}
+	return gl.Ptr(data)
+}
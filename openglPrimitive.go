@@ -0,0 +1,61 @@
+package main
+
+import (
+	"github.com/Trip1eLift/3d-engine-go/graphicsMath"
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// Line and point draws take vertices and colors like DrawTriangle: three
+// floats per position, four per color, one color per vertex. They go through
+// the current transforms, so set the camera first for world space lines.
+//
+// Core profile GL only guarantees lines one pixel wide.
+
+// DrawLine draws one segment, blending from fromColor to toColor.
+func (OGE *openglGraphicsEngine) DrawLine(from graphicsMath.Vec3, to graphicsMath.Vec3, fromColor RGB, toColor RGB) {
+	OGE.DrawLines(
+		[]float32{from.X, from.Y, from.Z, to.X, to.Y, to.Z},
+		[]float32{
+			fromColor.red, fromColor.green, fromColor.blue, 1,
+			toColor.red, toColor.green, toColor.blue, 1,
+		},
+	)
+}
+
+// DrawLines draws the vertices in pairs as separate segments.
+func (OGE *openglGraphicsEngine) DrawLines(vertices []float32, colors []float32) {
+	OGE.UpdateMesh(OGE.scratch, vertices, colors)
+	OGE.drawMeshPrimitives(OGE.scratch, gl.LINES)
+}
+
+// DrawPolyline connects each vertex to the next, and the last back to the
+// first when closed.
+func (OGE *openglGraphicsEngine) DrawPolyline(vertices []float32, colors []float32, closed bool) {
+	primitive := uint32(gl.LINE_STRIP)
+	if closed {
+		primitive = gl.LINE_LOOP
+	}
+	OGE.UpdateMesh(OGE.scratch, vertices, colors)
+	OGE.drawMeshPrimitives(OGE.scratch, primitive)
+}
+
+// DrawPoints draws each vertex as a square size pixels wide.
+func (OGE *openglGraphicsEngine) DrawPoints(vertices []float32, colors []float32, size float32) {
+	gl.PointSize(size)
+	OGE.UpdateMesh(OGE.scratch, vertices, colors)
+	OGE.drawMeshPrimitives(OGE.scratch, gl.POINTS)
+	gl.PointSize(1)
+}
+
+// ColorEvenly is one color repeated for vertexCount vertices.
+func (OGE *openglGraphicsEngine) ColorEvenly(color RGB, vertexCount int) []float32 {
+	return OGE.ColorEvenlyRGBA(color.WithAlpha(1), vertexCount)
+}
+
+func (OGE *openglGraphicsEngine) ColorEvenlyRGBA(color RGBA, vertexCount int) []float32 {
+	colors := make([]float32, 0, vertexCount*colorSize)
+	for i := 0; i < vertexCount; i++ {
+		colors = append(colors, color.red, color.green, color.blue, color.alpha)
+	}
+	return colors
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Trip1eLift/3d-engine-go/graphicsMath"
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// Vertex attributes are bound to the same locations in every program, so any
+// program can draw any meshBuffer.
+var vertexAttributes = []string{"vertice_point", "vertice_color", "vertice_uv"}
+
+const shaderReloadInterval = 250 * time.Millisecond
+
+// shaderProgram is a linked vertex + fragment program. Programs loaded from
+// files can recompile themselves when the files change on disk.
+type shaderProgram struct {
+	id             uint32
+	vertexPath     string
+	fragmentPath   string
+	vertexSource   string
+	fragmentSource string
+	uniforms       map[string]int32
+	values         map[string]uniformValue // last set, restored after a reload
+	hotReload      bool
+	modTime        time.Time
+	lastCheck      time.Time
+	failure        string    // last reload error reported, empty after a success
+	failureTime    time.Time // latest file modification when it was reported
+}
+
+func newShaderProgram(vertexSource string, fragmentSource string) (*shaderProgram, error) {
+	prog := &shaderProgram{
+		vertexSource:   vertexSource,
+		fragmentSource: fragmentSource,
+	}
+	if err := prog.build(); err != nil {
+		return nil, err
+	}
+	return prog, nil
+}
+
+// loadShaderProgram reads both stages from disk. With hotReload set,
+// reloadIfChanged picks up later edits.
+func loadShaderProgram(vertexPath string, fragmentPath string, hotReload bool) (*shaderProgram, error) {
+	prog := &shaderProgram{
+		vertexPath:   vertexPath,
+		fragmentPath: fragmentPath,
+		hotReload:    hotReload,
+	}
+	modTime, err := prog.readFiles()
+	if err != nil {
+		return nil, err
+	}
+	if err := prog.build(); err != nil {
+		return nil, err
+	}
+	prog.modTime = modTime
+	return prog, nil
+}
+
+// readFiles loads both sources and returns the latest modification time.
+// It is only the running program's once the sources build.
+func (prog *shaderProgram) readFiles() (time.Time, error) {
+	vertexSource, vertexTime, err := readShaderFile(prog.vertexPath)
+	if err != nil {
+		return time.Time{}, err
+	}
+	fragmentSource, fragmentTime, err := readShaderFile(prog.fragmentPath)
+	if err != nil {
+		return time.Time{}, err
+	}
+	prog.vertexSource = vertexSource
+	prog.fragmentSource = fragmentSource
+	return latest(vertexTime, fragmentTime), nil
+}
+
+// build compiles and links the current sources. On failure the previously
+// linked program, if any, is kept.
+func (prog *shaderProgram) build() error {
+	vertexShader, err := compileShader(prog.vertexSource, gl.VERTEX_SHADER, shaderName(prog.vertexPath, "vertex shader"))
+	if err != nil {
+		return err
+	}
+	defer gl.DeleteShader(vertexShader)
+	fragmentShader, err := compileShader(prog.fragmentSource, gl.FRAGMENT_SHADER, shaderName(prog.fragmentPath, "fragment shader"))
+	if err != nil {
+		return err
+	}
+	defer gl.DeleteShader(fragmentShader)
+
+	id := gl.CreateProgram()
+	gl.AttachShader(id, vertexShader)
+	gl.AttachShader(id, fragmentShader)
+	for location, name := range vertexAttributes {
+		gl.BindAttribLocation(id, uint32(location), gl.Str(name+"\x00"))
+	}
+	gl.LinkProgram(id)
+
+	var status int32
+	gl.GetProgramiv(id, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(id, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := strings.Repeat("\x00", int(logLength+1))
+		gl.GetProgramInfoLog(id, logLength, nil, gl.Str(log))
+		gl.DeleteProgram(id)
+
+		return &shaderError{stage: "link", name: "shader program", log: strings.TrimRight(log, "\x00")}
+	}
+
+	previous := prog.id
+	prog.id = id
+	prog.uniforms = make(map[string]int32)
+	if previous != 0 {
+		prog.restoreUniforms(previous)
+		gl.DeleteProgram(previous)
+	}
+	return nil
+}
+
+// restoreUniforms gives a rebuilt program the values the setters gave the one
+// it replaces, previous. A new program starts with every uniform at zero.
+func (prog *shaderProgram) restoreUniforms(previous uint32) {
+	if len(prog.values) == 0 {
+		return
+	}
+	var current int32
+	gl.GetIntegerv(gl.CURRENT_PROGRAM, &current)
+	gl.UseProgram(prog.id)
+	for name, value := range prog.values {
+		value.apply(prog.uniformLocation(name))
+	}
+	// The rebuilt program takes over if the old one was in use
+	if uint32(current) != previous {
+		gl.UseProgram(uint32(current))
+	}
+}
+
+// reloadIfChanged recompiles the program when its files are newer than the
+// running program. It reports whether a new program is now in use, with the
+// uniform values of the old one. A broken edit returns the compile or link
+// error and leaves the old program running; it is retried every check, but
+// a failure is returned once, and again only when the files or the error
+// change.
+func (prog *shaderProgram) reloadIfChanged() (bool, error) {
+	if !prog.hotReload || time.Since(prog.lastCheck) < shaderReloadInterval {
+		return false, nil
+	}
+	prog.lastCheck = time.Now()
+
+	reloaded, modTime, err := prog.reloadFiles()
+	if err == nil {
+		prog.failure = ""
+		return reloaded, nil
+	}
+	if err.Error() == prog.failure && modTime.Equal(prog.failureTime) {
+		return false, nil
+	}
+	prog.failure, prog.failureTime = err.Error(), modTime
+	return false, err
+}
+
+// reloadFiles rebuilds the program if either file is newer than the running
+// one. It also returns the latest modification time it could read.
+func (prog *shaderProgram) reloadFiles() (bool, time.Time, error) {
+	vertexInfo, err := os.Stat(prog.vertexPath)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	fragmentInfo, err := os.Stat(prog.fragmentPath)
+	if err != nil {
+		return false, vertexInfo.ModTime(), err
+	}
+	modTime := latest(vertexInfo.ModTime(), fragmentInfo.ModTime())
+	if !modTime.After(prog.modTime) {
+		return false, modTime, nil
+	}
+
+	readTime, err := prog.readFiles()
+	if err != nil {
+		return false, modTime, err
+	}
+	if err := prog.build(); err != nil {
+		return false, readTime, err
+	}
+	prog.modTime = readTime
+	return true, readTime, nil
+}
+
+func (prog *shaderProgram) use() {
+	gl.UseProgram(prog.id)
+}
+
+func (prog *shaderProgram) delete() {
+	gl.DeleteProgram(prog.id)
+	prog.id = 0
+}
+
+// uniformLocation is cached per name. Unknown names cache -1, which GL
+// silently ignores.
+func (prog *shaderProgram) uniformLocation(name string) int32 {
+	location, ok := prog.uniforms[name]
+	if !ok {
+		location = gl.GetUniformLocation(prog.id, gl.Str(name+"\x00"))
+		prog.uniforms[name] = location
+	}
+	return location
+}
+
+// ---------------------------- uniforms --------------------------
+
+type uniformKind int
+
+const (
+	UNIFORM_INT uniformKind = iota
+	UNIFORM_FLOAT
+	UNIFORM_VEC3
+	UNIFORM_VEC4
+	UNIFORM_MAT4
+)
+
+// uniformValue is what a setter was last called with.
+type uniformValue struct {
+	kind    uniformKind
+	integer int32
+	vector  graphicsMath.Vec4 // FLOAT uses X, VEC3 X, Y and Z
+	matrix  graphicsMath.Mat4
+}
+
+func (value *uniformValue) apply(location int32) {
+	switch value.kind {
+	case UNIFORM_INT:
+		gl.Uniform1i(location, value.integer)
+	case UNIFORM_FLOAT:
+		gl.Uniform1f(location, value.vector.X)
+	case UNIFORM_VEC3:
+		gl.Uniform3f(location, value.vector.X, value.vector.Y, value.vector.Z)
+	case UNIFORM_VEC4:
+		gl.Uniform4f(location, value.vector.X, value.vector.Y, value.vector.Z, value.vector.W)
+	case UNIFORM_MAT4:
+		// Mat4 is row-major for row vectors, which is exactly the
+		// column-major layout GLSL expects for its column vectors.
+		gl.UniformMatrix4fv(location, 1, false, &value.matrix.M[0][0])
+	}
+}
+
+func (prog *shaderProgram) setUniform(name string, value uniformValue) {
+	if prog.values == nil {
+		prog.values = make(map[string]uniformValue)
+	}
+	prog.values[name] = value
+	value.apply(prog.uniformLocation(name))
+}
+
+// The setters apply to the program currently in use. Values survive a hot
+// reload.
+
+func (prog *shaderProgram) SetInt(name string, value int32) {
+	prog.setUniform(name, uniformValue{kind: UNIFORM_INT, integer: value})
+}
+
+func (prog *shaderProgram) SetFloat(name string, value float32) {
+	prog.setUniform(name, uniformValue{kind: UNIFORM_FLOAT, vector: graphicsMath.Vec4{X: value}})
+}
+
+func (prog *shaderProgram) SetVec3(name string, value graphicsMath.Vec3) {
+	prog.setUniform(name, uniformValue{kind: UNIFORM_VEC3, vector: graphicsMath.Vec4{X: value.X, Y: value.Y, Z: value.Z}})
+}
+
+func (prog *shaderProgram) SetVec4(name string, value graphicsMath.Vec4) {
+	prog.setUniform(name, uniformValue{kind: UNIFORM_VEC4, vector: value})
+}
+
+func (prog *shaderProgram) SetMat4(name string, value graphicsMath.Mat4) {
+	prog.setUniform(name, uniformValue{kind: UNIFORM_MAT4, matrix: value})
+}
+
+// LoadShaderProgram loads a program the engine owns and deletes on
+// destruction. Use it with UseShaderProgram.
+func (OGE *openglGraphicsEngine) LoadShaderProgram(vertexPath string, fragmentPath string, hotReload bool) (*shaderProgram, error) {
+	prog, err := loadShaderProgram(vertexPath, fragmentPath, hotReload)
+	if err != nil {
+		return nil, err
+	}
+	OGE.shaders = append(OGE.shaders, prog)
+	return prog, nil
+}
+
+// CompileShaderProgram builds a program the engine owns from sources
+// without a #version line, which is added to match the context. The
+// built-in vertexShaderSource and fragmentShaderSource can be reused.
+func (OGE *openglGraphicsEngine) CompileShaderProgram(vertexSource string, fragmentSource string) (*shaderProgram, error) {
+	prog, err := newShaderProgram(OGE.glsl+vertexSource, OGE.glsl+fragmentSource)
+	if err != nil {
+		return nil, err
+	}
+	OGE.shaders = append(OGE.shaders, prog)
+	return prog, nil
+}
+
+// UseShaderProgram switches the following draws, and SetTransforms, to prog.
+// The transforms and bound texture are engine state, not the program's: they
+// are applied to prog's uniforms here, so they carry over. The default
+// program is restored at the start of every frame.
+func (OGE *openglGraphicsEngine) UseShaderProgram(prog *shaderProgram) {
+	OGE.program = prog
+	prog.use()
+	OGE.SetTransforms(OGE.model, OGE.view, OGE.projection)
+	if OGE.boundTexture != nil {
+		OGE.BindTexture(OGE.boundTexture)
+	} else {
+		OGE.UnbindTexture()
+	}
+}
+
+func (OGE *openglGraphicsEngine) reloadShaders() {
+	for _, prog := range OGE.shaders {
+		reloaded, err := prog.reloadIfChanged()
+		if err != nil {
+			log.Println("shader reload:", err)
+		} else if reloaded {
+			log.Println("shader reloaded:", prog.vertexPath, prog.fragmentPath)
+		}
+	}
+}
+
+// ------------------------  Helper funcsions -----------------------------------
+
+func readShaderFile(path string) (string, time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return string(source), info.ModTime(), nil
+}
+
+func shaderName(path string, fallback string) string {
+	if path == "" {
+		return fallback
+	}
+	return path
+}
+
+func latest(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Trip1eLift/3d-engine-go/graphicsMath"
+)
+
+// Text is drawn in screen space: x, y in pixels from the top-left corner of
+// the window, scale in screen pixels per font pixel. Newlines start a new
+// line at x.
+
+// drawStats counts what the current frame has drawn so far.
+type drawStats struct {
+	drawCalls int
+	triangles int
+}
+
+func (OGE *openglGraphicsEngine) initText() {
+	OGE.font = OGE.UploadTexture(fontAtlas(), TEXTURE_PIXELATED)
+	OGE.textBuffer = OGE.UploadMesh(nil, nil)
+}
+
+// TextSize is the size in pixels DrawText covers for text.
+func (OGE *openglGraphicsEngine) TextSize(text string, scale float32) (width float32, height float32) {
+	columns, maxColumns, lines := 0, 0, 1
+	for _, char := range text {
+		if char == '\n' {
+			columns = 0
+			lines++
+			continue
+		}
+		columns++
+		if columns > maxColumns {
+			maxColumns = columns
+		}
+	}
+	if maxColumns > 0 {
+		width = float32(maxColumns*FONT_CELL_W-1) * scale
+	}
+	return width, float32(lines*FONT_CELL_H-1) * scale
+}
+
+// DrawText draws over whatever is on screen with the default program, and
+// leaves the program, transforms, depth and blend state, culling and bound
+// texture as it found them.
+func (OGE *openglGraphicsEngine) DrawText(text string, x float32, y float32, scale float32, color RGB) {
+	atlasWidth, atlasHeight := float32(OGE.font.width), float32(OGE.font.height)
+	var vertices, colors, uvs []float32
+
+	penX, penY := x, y
+	for _, char := range text {
+		if char == '\n' {
+			penX = x
+			penY += FONT_CELL_H * scale
+			continue
+		}
+		if char != ' ' {
+			cellX, cellY := fontCell(char)
+			left, top := penX, penY
+			right, bottom := penX+FONT_GLYPH_W*scale, penY+FONT_GLYPH_H*scale
+			// The atlas is uploaded bottom-up, v grows towards the top row
+			u0, u1 := float32(cellX)/atlasWidth, float32(cellX+FONT_GLYPH_W)/atlasWidth
+			v0, v1 := 1-float32(cellY)/atlasHeight, 1-float32(cellY+FONT_GLYPH_H)/atlasHeight
+
+			vertices = append(vertices,
+				left, top, 0, right, top, 0, left, bottom, 0,
+				right, top, 0, right, bottom, 0, left, bottom, 0,
+			)
+			uvs = append(uvs,
+				u0, v0, u1, v0, u0, v1,
+				u1, v0, u1, v1, u0, v1,
+			)
+			colors = append(colors, OGE.TriangleColorEvenly(color)...)
+			colors = append(colors, OGE.TriangleColorEvenly(color)...)
+		}
+		penX += FONT_CELL_W * scale
+	}
+	if len(vertices) == 0 {
+		return
+	}
+
+	program := OGE.program
+	model, view, projection := OGE.model, OGE.view, OGE.projection
+	depth, blend, culling, bound := OGE.depth, OGE.blend, OGE.culling, OGE.boundTexture
+
+	// A custom program may not sample the atlas or take screen coordinates
+	OGE.UseShaderProgram(OGE.shaders[0])
+	identity := graphicsMath.MakeIdentity()
+	screen := graphicsMath.MakeOrthographic(0, float32(OGE.screenWidth), float32(OGE.screenHeight), 0, -1, 1)
+	OGE.SetTransforms(identity, identity, screen)
+	OGE.SetDepthState(DEPTH_NONE)
+	OGE.SetBackfaceCulling(false)
+	OGE.SetBlendState(BLEND_ALPHA)
+
+	OGE.UpdateMesh(OGE.textBuffer, vertices, colors)
+	OGE.UpdateMeshUVs(OGE.textBuffer, uvs)
+	OGE.BindTexture(OGE.font)
+	OGE.FillMesh(OGE.textBuffer)
+	// Texturing is a uniform of the default program, turn it off there
+	// before leaving it or its next untextured draw samples nothing
+	OGE.UnbindTexture()
+
+	// The program first, the uniforms below are set on it
+	OGE.UseShaderProgram(program)
+	OGE.SetTransforms(model, view, projection)
+	OGE.SetDepthState(depth)
+	OGE.SetBlendState(blend)
+	OGE.SetBackfaceCulling(culling)
+	if bound != nil {
+		OGE.BindTexture(bound)
+	} else {
+		OGE.UnbindTexture()
+	}
+}
+
+// ---------------------------- HUD --------------------------
+
+const HUD_SCALE = 2
+
+func (OGE *openglGraphicsEngine) SetHUDVisible(visible bool) {
+	OGE.hudVisible = visible
+}
+
+func (OGE *openglGraphicsEngine) ToggleHUD() {
+	OGE.hudVisible = !OGE.hudVisible
+}
+
+// drawHUD runs after every element has rendered, so the counts cover the
+// whole frame except the HUD itself.
+func (OGE *openglGraphicsEngine) drawHUD() {
+	if !OGE.hudVisible {
+		return
+	}
+	stats := OGE.stats
+	fps := 0.0
+	if OGE.frameTimeAverage > 0 {
+		fps = 1 / OGE.frameTimeAverage
+	}
+	text := fmt.Sprintf("FPS %.1f\nframe %.2f ms\ndraw calls %d\ntriangles %d",
+		fps, OGE.frameTimeAverage*1000, stats.drawCalls, stats.triangles)
+
+	// Shadow keeps it readable on bright scenes
+	OGE.DrawText(text, 8+HUD_SCALE, 8+HUD_SCALE, HUD_SCALE, RGB{0, 0, 0})
+	OGE.DrawText(text, 8, 8, HUD_SCALE, WHITE)
+}
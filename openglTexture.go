@@ -0,0 +1,111 @@
+package main
+
+import (
+	"image"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// textureOptions are GL sampler parameters. Mipmapped min filters such as
+// gl.LINEAR_MIPMAP_LINEAR need mipmaps set.
+type textureOptions struct {
+	minFilter int32
+	magFilter int32
+	wrapS     int32
+	wrapT     int32
+	mipmaps   bool
+}
+
+var (
+	TEXTURE_SMOOTH    = textureOptions{gl.LINEAR_MIPMAP_LINEAR, gl.LINEAR, gl.REPEAT, gl.REPEAT, true}
+	TEXTURE_PIXELATED = textureOptions{gl.NEAREST, gl.NEAREST, gl.CLAMP_TO_EDGE, gl.CLAMP_TO_EDGE, false}
+)
+
+type texture struct {
+	id     uint32
+	width  int
+	height int
+}
+
+// LoadTexture decodes a PNG or JPEG file and uploads it.
+func (OGE *openglGraphicsEngine) LoadTexture(path string, options textureOptions) (*texture, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+	return OGE.UploadTexture(img, options), nil
+}
+
+// UploadTexture copies img to the GPU. UV (0, 0) is the bottom-left corner of
+// the image and (1, 1) the top-right.
+func (OGE *openglGraphicsEngine) UploadTexture(img image.Image, options textureOptions) *texture {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// GL reads rows bottom-up
+	rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	flipped := make([]uint8, len(rgba.Pix))
+	for y := 0; y < height; y++ {
+		copy(flipped[y*rgba.Stride:(y+1)*rgba.Stride], rgba.Pix[(height-1-y)*rgba.Stride:(height-y)*rgba.Stride])
+	}
+
+	tex := &texture{width: width, height: height}
+	gl.GenTextures(1, &tex.id)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, tex.id)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, options.minFilter)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, options.magFilter)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, options.wrapS)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, options.wrapT)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, int32(width), int32(height), 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(flipped))
+	if options.mipmaps {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+
+	OGE.textures[tex] = struct{}{}
+	return tex
+}
+
+// BindTexture makes the following draws sample tex, tinted by their vertex
+// colors, also after UseShaderProgram. It is unbound at the start of every
+// frame.
+func (OGE *openglGraphicsEngine) BindTexture(tex *texture) {
+	OGE.boundTexture = tex
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, tex.id)
+	OGE.program.SetInt("tex", 0)
+	OGE.program.SetInt("useTexture", 1)
+}
+
+// UnbindTexture returns the following draws to plain vertex colors.
+func (OGE *openglGraphicsEngine) UnbindTexture() {
+	OGE.boundTexture = nil
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	OGE.program.SetInt("useTexture", 0)
+}
+
+// ReleaseTexture frees tex. It must not be bound again.
+func (OGE *openglGraphicsEngine) ReleaseTexture(tex *texture) {
+	if _, ok := OGE.textures[tex]; !ok {
+		return
+	}
+	gl.DeleteTextures(1, &tex.id)
+	delete(OGE.textures, tex)
+}
+
+func (OGE *openglGraphicsEngine) releaseAllTextures() {
+	for tex := range OGE.textures {
+		OGE.ReleaseTexture(tex)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// Elements implementing resizeListener are told the new framebuffer size,
+// in pixels, whenever the window is resized or changes fullscreen state.
+type resizeListener interface {
+	onResize(width int, height int)
+}
+
+type windowedPlacement struct {
+	x, y          int
+	width, height int
+}
+
+func (OGE *openglGraphicsEngine) initWindow() {
+	OGE.window.SetFramebufferSizeCallback(func(w *glfw.Window, width int, height int) {
+		OGE.resize(width, height)
+	})
+
+	// On HiDPI displays the framebuffer is larger than the requested window
+	OGE.resize(OGE.window.GetFramebufferSize())
+}
+
+func (OGE *openglGraphicsEngine) resize(width int, height int) {
+	// Minimized windows report 0x0, keep the last usable size
+	if width <= 0 || height <= 0 {
+		return
+	}
+	gl.Viewport(0, 0, int32(width), int32(height))
+	OGE.screenWidth = width
+	OGE.screenHeight = height
+	OGE.camera.AspectRatio = float32(height) / float32(width)
+
+	for _, entry := range OGE.elements {
+		if !entry.created || entry.removed {
+			continue
+		}
+		if listener, ok := entry.ele.(resizeListener); ok {
+			listener.onResize(width, height)
+		}
+	}
+}
+
+func (OGE *openglGraphicsEngine) IsFullscreen() bool {
+	return OGE.window.GetMonitor() != nil
+}
+
+// SetFullscreen moves the window to the primary monitor at its current video
+// mode, or back to where it was before.
+func (OGE *openglGraphicsEngine) SetFullscreen(fullscreen bool) {
+	if fullscreen == OGE.IsFullscreen() {
+		return
+	}
+	if fullscreen {
+		OGE.windowed.x, OGE.windowed.y = OGE.window.GetPos()
+		OGE.windowed.width, OGE.windowed.height = OGE.window.GetSize()
+
+		monitor := glfw.GetPrimaryMonitor()
+		mode := monitor.GetVideoMode()
+		OGE.window.SetMonitor(monitor, 0, 0, mode.Width, mode.Height, mode.RefreshRate)
+	} else {
+		OGE.window.SetMonitor(nil, OGE.windowed.x, OGE.windowed.y, OGE.windowed.width, OGE.windowed.height, 0)
+	}
+}
+
+func (OGE *openglGraphicsEngine) ToggleFullscreen() {
+	OGE.SetFullscreen(!OGE.IsFullscreen())
+}